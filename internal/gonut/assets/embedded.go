@@ -0,0 +1,125 @@
+// Copyright © 2019 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package assets
+
+import (
+	"fmt"
+
+	"github.com/homeport/pina-golada/pkg/files"
+)
+
+// embedded returns the loader of an embedded sample app, which only accesses
+// the provider once the sample app is loaded, since the generated code sets
+// the provider during package initialization
+func embedded(load func(ProviderInterface) (files.Directory, error)) func() (files.Directory, error) {
+	return func() (files.Directory, error) {
+		if Provider == nil {
+			return nil, fmt.Errorf("embedded sample apps are not available, the binary was built without the generated asset code")
+		}
+
+		return load(Provider)
+	}
+}
+
+func init() {
+	for _, app := range []SampleApp{
+		{
+			Name:      "golang",
+			Caption:   "Golang",
+			Buildpack: "go_buildpack",
+			Aliases:   []string{"go"},
+			Load:      embedded(ProviderInterface.GoSampleApp),
+		},
+
+		{
+			Name:      "python",
+			Caption:   "Python",
+			Buildpack: "python_buildpack",
+			Load:      embedded(ProviderInterface.PythonSampleApp),
+		},
+
+		{
+			Name:      "php",
+			Caption:   "PHP",
+			Buildpack: "php_buildpack",
+			Load:      embedded(ProviderInterface.PHPSampleApp),
+		},
+
+		{
+			Name:      "staticfile",
+			Caption:   "Staticfile",
+			Buildpack: "staticfile_buildpack",
+			Aliases:   []string{"static"},
+			Load:      embedded(ProviderInterface.StaticfileSampleApp),
+		},
+
+		{
+			Name:      "swift",
+			Caption:   "Swift",
+			Buildpack: "swift_buildpack",
+			Load:      embedded(ProviderInterface.SwiftSampleApp),
+		},
+
+		{
+			Name:      "nodejs",
+			Caption:   "NodeJS",
+			Buildpack: "nodejs_buildpack",
+			Aliases:   []string{"node"},
+			Load:      embedded(ProviderInterface.NodeJSSampleApp),
+		},
+
+		{
+			Name:      "ruby",
+			Caption:   "Ruby",
+			Buildpack: "ruby_buildpack",
+			Load:      embedded(ProviderInterface.RubySampleApp),
+
+			AppNameInfix: "ruby-sinatra-app-",
+		},
+
+		{
+			Name:      "dotnet",
+			Caption:   ".NET",
+			Buildpack: "dotnet-core",
+			Aliases:   []string{"net"},
+			Load:      embedded(ProviderInterface.DotNetSampleApp),
+		},
+
+		{
+			Name:      "binary",
+			Caption:   "Binary",
+			Buildpack: "binary_buildpack",
+			Load:      embedded(ProviderInterface.BinarySampleApp),
+		},
+
+		{
+			Name:      "java",
+			Caption:   "Java",
+			Buildpack: "java_buildpack",
+			Load:      embedded(ProviderInterface.JavaSampleApp),
+		},
+	} {
+		app.Kind = EmbeddedApp
+		if err := SampleApps.Register(app); err != nil {
+			panic(err)
+		}
+	}
+}
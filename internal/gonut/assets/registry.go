@@ -0,0 +1,123 @@
+// Copyright © 2019 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package assets
+
+import (
+	"fmt"
+
+	"github.com/homeport/pina-golada/pkg/files"
+)
+
+// Kinds of sample apps
+const (
+	EmbeddedApp = "embedded"
+	UserApp     = "user"
+	RemoteApp   = "remote"
+)
+
+// DefaultHealthCheckEndpoint is the path used for http health checks of
+// sample apps that do not define a diagnostic endpoint
+const DefaultHealthCheckEndpoint = "/"
+
+// SampleApp describes a sample app and how to load its files, regardless of
+// whether it is embedded into the binary, user-defined, or a remote source
+type SampleApp struct {
+	Name       string
+	Caption    string
+	Kind       string
+	Buildpack  string
+	Stack      string
+	Aliases    []string
+	Probes     []string
+	StatusCode int
+	Load       func() (files.Directory, error)
+
+	// AppNameInfix is used in the names of the pushed applications, it
+	// defaults to the name of the sample app followed by "-app-"
+	AppNameInfix string
+
+	// HealthCheckEndpoint is the path of the diagnostic endpoint that is used
+	// for http health checks, the root path is used if it is not set
+	HealthCheckEndpoint string
+
+	// Commit returns the SHA of the checked out commit of git based sample
+	// apps once they are loaded, it is optional
+	Commit func() string
+}
+
+// Registry contains sample apps in the order they were registered
+type Registry struct {
+	apps []SampleApp
+}
+
+// AllSampleApps is the keyword that stands for all known sample apps, so it
+// cannot be used as the name or alias of a sample app
+const AllSampleApps = "all"
+
+// SampleApps is the registry of all sample apps known to gonut
+var SampleApps = &Registry{}
+
+// Register adds the sample app to the registry, its name and aliases must
+// not be used by another sample app already
+func (registry *Registry) Register(app SampleApp) error {
+	if app.Name == "" {
+		return fmt.Errorf("sample app %q has no name", app.Caption)
+	}
+
+	if app.Load == nil {
+		return fmt.Errorf("sample app %s has no loader", app.Name)
+	}
+
+	for _, name := range append([]string{app.Name}, app.Aliases...) {
+		if name == AllSampleApps {
+			return fmt.Errorf("sample app %s uses the name %s, which is reserved for all sample apps", app.Name, name)
+		}
+
+		if existing, ok := registry.Lookup(name); ok {
+			return fmt.Errorf("sample app %s uses the name %s, which is already used by the %s sample app", app.Name, name, existing.Caption)
+		}
+	}
+
+	registry.apps = append(registry.apps, app)
+	return nil
+}
+
+// Lookup returns the sample app with the given name or alias
+func (registry *Registry) Lookup(name string) (SampleApp, bool) {
+	for _, app := range registry.apps {
+		if app.Name == name {
+			return app, true
+		}
+
+		for _, alias := range app.Aliases {
+			if alias == name {
+				return app, true
+			}
+		}
+	}
+
+	return SampleApp{}, false
+}
+
+// Apps returns all registered sample apps
+func (registry *Registry) Apps() []SampleApp {
+	return append([]SampleApp{}, registry.apps...)
+}
@@ -0,0 +1,90 @@
+// Copyright © 2019 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package assets_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/homeport/gonut/internal/gonut/assets"
+	"github.com/homeport/pina-golada/pkg/files"
+)
+
+var _ = Describe("Sample app registry", func() {
+	var (
+		registry *Registry
+		load     = func() (files.Directory, error) { return files.NewRootDirectory(), nil }
+	)
+
+	BeforeEach(func() {
+		registry = &Registry{}
+		Expect(registry.Register(SampleApp{Name: "golang", Caption: "Golang", Aliases: []string{"go"}, Load: load})).To(Succeed())
+		Expect(registry.Register(SampleApp{Name: "python", Caption: "Python", Load: load})).To(Succeed())
+	})
+
+	It("should list the sample apps in order of registration", func() {
+		var names []string
+		for _, app := range registry.Apps() {
+			names = append(names, app.Name)
+		}
+
+		Expect(names).To(Equal([]string{"golang", "python"}))
+	})
+
+	It("should look up sample apps by name and alias", func() {
+		app, ok := registry.Lookup("go")
+		Expect(ok).To(BeTrue())
+		Expect(app.Caption).To(Equal("Golang"))
+
+		_, ok = registry.Lookup("ruby")
+		Expect(ok).To(BeFalse())
+	})
+
+	It("should refuse sample apps using a name that is already taken", func() {
+		Expect(registry.Register(SampleApp{Name: "go", Load: load})).ToNot(Succeed())
+		Expect(registry.Register(SampleApp{Name: "golang2", Aliases: []string{"python"}, Load: load})).ToNot(Succeed())
+		Expect(registry.Apps()).To(HaveLen(2))
+	})
+
+	It("should refuse sample apps using the name reserved for all sample apps", func() {
+		Expect(registry.Register(SampleApp{Name: AllSampleApps, Load: load})).ToNot(Succeed())
+		Expect(registry.Register(SampleApp{Name: "ruby", Aliases: []string{AllSampleApps}, Load: load})).ToNot(Succeed())
+		Expect(registry.Apps()).To(HaveLen(2))
+	})
+
+	It("should refuse sample apps without name or loader", func() {
+		Expect(registry.Register(SampleApp{Caption: "Nameless", Load: load})).ToNot(Succeed())
+		Expect(registry.Register(SampleApp{Name: "ruby"})).ToNot(Succeed())
+	})
+
+	It("should contain the embedded sample apps", func() {
+		apps := SampleApps.Apps()
+		Expect(len(apps)).To(BeNumerically(">=", 10))
+
+		for _, app := range apps[:10] {
+			Expect(app.Kind).To(Equal(EmbeddedApp))
+			Expect(app.Buildpack).ToNot(BeEmpty())
+		}
+
+		_, ok := SampleApps.Lookup("node")
+		Expect(ok).To(BeTrue())
+	})
+})
@@ -0,0 +1,310 @@
+// Copyright © 2019 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cf
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/homeport/pina-golada/pkg/files"
+	"github.com/homeport/pina-golada/pkg/files/paths"
+
+	"github.com/homeport/gonut/internal/gonut/nok"
+)
+
+// ArchiveDownloadTimeout is the maximum time to download a sample app archive
+var ArchiveDownloadTimeout = 5 * time.Minute
+
+// MaxArchiveSize is the maximum size of a downloaded sample app archive
+var MaxArchiveSize int64 = 512 * 1024 * 1024
+
+// archiveEntry is a regular file of an archive
+type archiveEntry struct {
+	name string
+	mode os.FileMode
+	open func() (io.Reader, error)
+}
+
+// IsArchiveURL returns whether the URL refers to a supported archive, which
+// is a zip, jar, or gzipped tar file
+func IsArchiveURL(rootURL string) bool {
+	u, err := url.Parse(rootURL)
+	if err != nil {
+		return false
+	}
+
+	return archiveFormat(u.Path) != ""
+}
+
+func archiveFormat(name string) string {
+	name = strings.ToLower(name)
+	switch {
+	case strings.HasSuffix(name, ".zip"), strings.HasSuffix(name, ".jar"):
+		return "zip"
+
+	case strings.HasSuffix(name, ".tar.gz"), strings.HasSuffix(name, ".tgz"):
+		return "tar.gz"
+
+	default:
+		return ""
+	}
+}
+
+// LoadSampleAppArchive returns an in-memory directory with the contents of
+// the archive at the given file or HTTP(S) URL. Only the contents of the
+// relative path inside the archive are used, if one is given. In case a
+// SHA-256 checksum is provided, the archive has to match it.
+func LoadSampleAppArchive(rootURL string, checksum string, relativePath string) (files.Directory, error) {
+	caption := fmt.Sprintf("failed to load sample app archive %s", RedactURL(rootURL))
+
+	u, err := url.Parse(rootURL)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := readArchive(u)
+	if err != nil {
+		return nil, nok.Errorf(caption, err.Error())
+	}
+
+	if checksum != "" {
+		sum := sha256.Sum256(data)
+		if actual := hex.EncodeToString(sum[:]); !strings.EqualFold(actual, checksum) {
+			return nil, nok.Errorf(caption, "the SHA-256 checksum of the archive is %s, but %s is expected", actual, checksum)
+		}
+	}
+
+	var entries []archiveEntry
+	switch archiveFormat(u.Path) {
+	case "zip":
+		entries, err = zipEntries(data)
+
+	case "tar.gz":
+		entries, err = tarEntries(data)
+
+	default:
+		err = fmt.Errorf("unsupported archive format, supported are zip, jar, tar.gz, and tgz")
+	}
+
+	if err != nil {
+		return nil, nok.Errorf(caption, err.Error())
+	}
+
+	directory, err := extractArchive(entries, relativePath)
+	if err != nil {
+		return nil, nok.Errorf(caption, err.Error())
+	}
+
+	return directory, nil
+}
+
+func readArchive(u *url.URL) ([]byte, error) {
+	switch u.Scheme {
+	case "file":
+		return os.ReadFile(u.Path)
+
+	case "http", "https":
+		client := &http.Client{Timeout: ArchiveDownloadTimeout}
+		resp, err := client.Get(u.String())
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("download failed with status code %d", resp.StatusCode)
+		}
+
+		if resp.ContentLength > MaxArchiveSize {
+			return nil, fmt.Errorf("archive size of %s exceeds the limit of %s", HumanReadableSize(resp.ContentLength), HumanReadableSize(MaxArchiveSize))
+		}
+
+		// Read one byte more than allowed to detect archives that are too large
+		data, err := io.ReadAll(io.LimitReader(resp.Body, MaxArchiveSize+1))
+		if err != nil {
+			return nil, err
+		}
+
+		if int64(len(data)) > MaxArchiveSize {
+			return nil, fmt.Errorf("archive size exceeds the limit of %s", HumanReadableSize(MaxArchiveSize))
+		}
+
+		return data, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported URL scheme %q, supported are file, http, and https", u.Scheme)
+	}
+}
+
+func zipEntries(data []byte) ([]archiveEntry, error) {
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []archiveEntry
+	for _, file := range reader.File {
+		if !file.Mode().IsRegular() {
+			continue
+		}
+
+		file := file
+		entries = append(entries, archiveEntry{
+			name: file.Name,
+			mode: file.Mode(),
+			open: func() (io.Reader, error) {
+				rc, err := file.Open()
+				if err != nil {
+					return nil, err
+				}
+				defer rc.Close()
+
+				// The file is read completely, so that it can be closed here
+				content, err := io.ReadAll(rc)
+				return bytes.NewReader(content), err
+			},
+		})
+	}
+
+	return entries, nil
+}
+
+func tarEntries(data []byte) ([]archiveEntry, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+
+	var entries []archiveEntry
+	reader := tar.NewReader(gz)
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		content, err := io.ReadAll(reader)
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, archiveEntry{
+			name: header.Name,
+			mode: header.FileInfo().Mode(),
+			open: func() (io.Reader, error) { return bytes.NewReader(content), nil },
+		})
+	}
+
+	return entries, nil
+}
+
+// extractArchive creates an in-memory directory with the archive entries that
+// are located in the relative path, files listed in the .cfignore file of the
+// sample app and version control directories are skipped
+func extractArchive(entries []archiveEntry, relativePath string) (files.Directory, error) {
+	prefix := strings.Trim(path.Clean("/"+relativePath), "/")
+
+	var (
+		selected []archiveEntry
+		cfignore string
+	)
+
+	for _, entry := range entries {
+		// Do not allow entries to escape the sample app directory
+		name := path.Clean(entry.name)
+		if path.IsAbs(name) || name == ".." || strings.HasPrefix(name, "../") {
+			return nil, fmt.Errorf("archive contains the invalid path %s", entry.name)
+		}
+
+		if prefix != "" {
+			if !strings.HasPrefix(name, prefix+"/") {
+				continue
+			}
+
+			name = strings.TrimPrefix(name, prefix+"/")
+		}
+
+		if name == CFIgnoreFile {
+			reader, err := entry.open()
+			if err != nil {
+				return nil, err
+			}
+
+			content, err := io.ReadAll(reader)
+			if err != nil {
+				return nil, err
+			}
+
+			cfignore = string(content)
+		}
+
+		entry.name = name
+		selected = append(selected, entry)
+	}
+
+	if len(selected) == 0 {
+		if prefix != "" {
+			return nil, fmt.Errorf("archive does not contain any files in %s", prefix)
+		}
+
+		return nil, fmt.Errorf("archive does not contain any files")
+	}
+
+	matcher := newIgnoreMatcher(cfignore)
+	directory := files.NewRootDirectory()
+	for _, entry := range selected {
+		if matcher.Match(strings.Split(entry.name, "/"), false) {
+			continue
+		}
+
+		reader, err := entry.open()
+		if err != nil {
+			return nil, err
+		}
+
+		if err := directory.NewFile(paths.Of(entry.name)).WithPermission(entry.mode).Write(reader); err != nil {
+			return nil, err
+		}
+	}
+
+	return directory, nil
+}
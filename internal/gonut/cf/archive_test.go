@@ -0,0 +1,292 @@
+// Copyright © 2019 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cf_test
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/homeport/pina-golada/pkg/files"
+	"github.com/homeport/pina-golada/pkg/files/paths"
+
+	. "github.com/homeport/gonut/internal/gonut/cf"
+	"github.com/homeport/gonut/internal/gonut/nok"
+)
+
+var _ = Describe("Sample App Archives", func() {
+	var tmp string
+
+	content := map[string]string{
+		"sample-app/index.js":          "console.log('Hello Gonut!')",
+		"sample-app/package.json":      "{}",
+		"sample-app/public/index.html": "<html></html>",
+		"README.md":                    "# Sample App",
+	}
+
+	zipArchive := func(files map[string]string) []byte {
+		var buf bytes.Buffer
+		w := zip.NewWriter(&buf)
+		for name, data := range files {
+			f, err := w.Create(name)
+			Expect(err).ToNot(HaveOccurred())
+			_, err = f.Write([]byte(data))
+			Expect(err).ToNot(HaveOccurred())
+		}
+
+		Expect(w.Close()).To(Succeed())
+		return buf.Bytes()
+	}
+
+	tarGzArchive := func(files map[string]string) []byte {
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		w := tar.NewWriter(gz)
+		for name, data := range files {
+			Expect(w.WriteHeader(&tar.Header{Name: name, Mode: 0755, Size: int64(len(data)), Typeflag: tar.TypeReg})).To(Succeed())
+			_, err := w.Write([]byte(data))
+			Expect(err).ToNot(HaveOccurred())
+		}
+
+		Expect(w.Close()).To(Succeed())
+		Expect(gz.Close()).To(Succeed())
+		return buf.Bytes()
+	}
+
+	write := func(name string, data []byte) string {
+		path := filepath.Join(tmp, name)
+		Expect(os.WriteFile(path, data, 0644)).To(Succeed())
+		return "file://" + path
+	}
+
+	fileNames := func(directory files.Directory) []string {
+		var result []string
+		files.WalkFileTree(directory, func(file files.File) {
+			result = append(result, file.AbsolutePath().String())
+		})
+
+		sort.Strings(result)
+		return result
+	}
+
+	read := func(directory files.Directory, path string) string {
+		var buf bytes.Buffer
+		Expect(directory.File(paths.Of(path)).CopyContent(&buf)).To(Succeed())
+		return buf.String()
+	}
+
+	checksum := func(data []byte) string {
+		sum := sha256.Sum256(data)
+		return hex.EncodeToString(sum[:])
+	}
+
+	BeforeEach(func() {
+		var err error
+		tmp, err = os.MkdirTemp("", "gonut-archive")
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(tmp)
+	})
+
+	Context("detecting archives", func() {
+		It("should detect the supported archive formats", func() {
+			Expect(IsArchiveURL("file:///tmp/app.zip")).To(BeTrue())
+			Expect(IsArchiveURL("https://example.org/app.jar")).To(BeTrue())
+			Expect(IsArchiveURL("https://example.org/app.tar.gz")).To(BeTrue())
+			Expect(IsArchiveURL("https://example.org/app.TGZ?download=true")).To(BeTrue())
+			Expect(IsArchiveURL("https://github.com/homeport/gonut-sample-apps")).To(BeFalse())
+			Expect(IsArchiveURL("file:///tmp/app")).To(BeFalse())
+		})
+	})
+
+	Context("loading archives from file URLs", func() {
+		It("should extract zip archives", func() {
+			directory, err := LoadSampleAppArchive(write("app.zip", zipArchive(content)), "", "")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(fileNames(directory)).To(Equal([]string{
+				"/README.md",
+				"/sample-app/index.js",
+				"/sample-app/package.json",
+				"/sample-app/public/index.html",
+			}))
+		})
+
+		It("should extract jar archives like zip archives", func() {
+			directory, err := LoadSampleAppArchive(write("app.jar", zipArchive(content)), "", "")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(fileNames(directory)).To(HaveLen(4))
+		})
+
+		It("should extract gzipped tar archives including the file permissions", func() {
+			directory, err := LoadSampleAppArchive(write("app.tar.gz", tarGzArchive(content)), "", "")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(fileNames(directory)).To(HaveLen(4))
+			Expect(directory.File(paths.Of("sample-app/index.js")).PermissionSet()).To(Equal(os.FileMode(0755)))
+		})
+
+		It("should only use the contents of the relative path", func() {
+			directory, err := LoadSampleAppArchive(write("app.zip", zipArchive(content)), "", "sample-app/")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(fileNames(directory)).To(Equal([]string{
+				"/index.js",
+				"/package.json",
+				"/public/index.html",
+			}))
+
+			Expect(read(directory, "index.js")).To(Equal("console.log('Hello Gonut!')"))
+		})
+
+		It("should fail when the relative path does not exist in the archive", func() {
+			_, err := LoadSampleAppArchive(write("app.zip", zipArchive(content)), "", "other")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("does not contain any files in other"))
+		})
+
+		It("should fail for archives with paths outside of the sample app", func() {
+			_, err := LoadSampleAppArchive(write("app.zip", zipArchive(map[string]string{"../evil.sh": "rm -rf /"})), "", "")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("invalid path ../evil.sh"))
+		})
+
+		It("should fail for files that are no valid archives", func() {
+			_, err := LoadSampleAppArchive(write("app.zip", []byte("no zip")), "", "")
+			Expect(err).To(BeAssignableToTypeOf(&nok.ErrorWithDetails{}))
+		})
+
+		It("should be supported when loading sample apps by URL", func() {
+			directory, err := LoadSampleAppURL(write("app.tgz", tarGzArchive(content)), "sample-app", "")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(fileNames(directory)).To(HaveLen(3))
+		})
+	})
+
+	Context("verifying checksums", func() {
+		It("should accept archives that match the checksum", func() {
+			data := zipArchive(content)
+			_, err := LoadSampleAppArchive(write("app.zip", data), checksum(data), "")
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("should reject archives that do not match the checksum", func() {
+			data := zipArchive(content)
+			_, err := LoadSampleAppArchive(write("app.zip", data), checksum([]byte("other")), "")
+			Expect(err).To(BeAssignableToTypeOf(&nok.ErrorWithDetails{}))
+			Expect(err.Error()).To(ContainSubstring("SHA-256 checksum of the archive is " + checksum(data)))
+		})
+	})
+
+	Context("loading archives over HTTP", func() {
+		var server *httptest.Server
+
+		BeforeEach(func() {
+			data := zipArchive(content)
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/app.zip" {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+
+				_, _ = w.Write(data)
+			}))
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		It("should download and extract the archive", func() {
+			directory, err := LoadSampleAppArchive(server.URL+"/app.zip", "", "sample-app")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(fileNames(directory)).To(HaveLen(3))
+		})
+
+		It("should fail when the download fails", func() {
+			_, err := LoadSampleAppArchive(server.URL+"/missing.zip", "", "")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("status code 404"))
+		})
+
+		Context("with limits", func() {
+			var (
+				previousTimeout time.Duration
+				previousSize    int64
+			)
+
+			BeforeEach(func() {
+				previousTimeout, previousSize = ArchiveDownloadTimeout, MaxArchiveSize
+			})
+
+			AfterEach(func() {
+				ArchiveDownloadTimeout, MaxArchiveSize = previousTimeout, previousSize
+			})
+
+			It("should fail for archives larger than the maximum size", func() {
+				MaxArchiveSize = 16
+
+				_, err := LoadSampleAppArchive(server.URL+"/app.zip", "", "")
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("exceeds the limit"))
+			})
+
+			It("should fail for archives without size that grow beyond the maximum size", func() {
+				MaxArchiveSize = 16
+				streaming := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					// Flushing before writing the content avoids a Content-Length header
+					w.(http.Flusher).Flush()
+					_, _ = w.Write(zipArchive(content))
+				}))
+				defer streaming.Close()
+
+				_, err := LoadSampleAppArchive(streaming.URL+"/app.zip", "", "")
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("exceeds the limit"))
+			})
+
+			It("should fail for downloads that stall", func() {
+				ArchiveDownloadTimeout = 50 * time.Millisecond
+				stalled := make(chan struct{})
+				slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					<-stalled
+				}))
+				defer slow.Close()
+				defer close(stalled)
+
+				_, err := LoadSampleAppArchive(slow.URL+"/app.zip", "", "")
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Timeout"))
+			})
+		})
+	})
+})
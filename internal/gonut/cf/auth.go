@@ -0,0 +1,268 @@
+// Copyright © 2019 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cf
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/go-git/go-git/v5/plumbing/transport/ssh"
+
+	"github.com/homeport/gonut/internal/gonut/nok"
+)
+
+// Environment variables to configure the authentication against private git
+// repositories
+const (
+	GitTokenEnv         = "GONUT_GIT_TOKEN"
+	GitTokenHostEnv     = "GONUT_GIT_TOKEN_HOST"
+	GitUsernameEnv      = "GONUT_GIT_USERNAME"
+	SSHKeyEnv           = "GONUT_SSH_KEY"
+	SSHKeyPassphraseEnv = "GONUT_SSH_KEY_PASSPHRASE"
+)
+
+// defaultGitUsername is used together with a token, most git servers ignore
+// the username in this case, but require one to be set
+const defaultGitUsername = "gonut"
+
+// gitAuth returns the authentication method for the given git repository URL,
+// which is nil in case no credentials are configured for it:
+//
+// - SSH URLs use the key file configured in GONUT_SSH_KEY, or the SSH agent
+// - HTTP(S) URLs use the credentials in the URL, the token configured in
+// GONUT_GIT_TOKEN, or the matching entry of the ~/.netrc file. The token is
+// only sent over HTTPS, and only to the hosts listed in GONUT_GIT_TOKEN_HOST
+// in case it is set.
+func gitAuth(url string) (transport.AuthMethod, error) {
+	endpoint, err := transport.NewEndpoint(url)
+	if err != nil {
+		return nil, err
+	}
+
+	switch endpoint.Protocol {
+	case "ssh":
+		user := endpoint.User
+		if user == "" {
+			user = ssh.DefaultUsername
+		}
+
+		if keyFile, ok := os.LookupEnv(SSHKeyEnv); ok {
+			auth, err := ssh.NewPublicKeysFromFile(user, ExpandHomeDir(keyFile), os.Getenv(SSHKeyPassphraseEnv))
+			if err != nil {
+				return nil, nok.Errorf(
+					fmt.Sprintf("failed to load SSH key %s configured in %s", keyFile, SSHKeyEnv),
+					err.Error(),
+				)
+			}
+
+			return auth, nil
+		}
+
+		if _, ok := os.LookupEnv("SSH_AUTH_SOCK"); ok {
+			return ssh.NewSSHAgentAuth(user)
+		}
+
+	case "http", "https":
+		// Credentials in the URL itself are used by the transport directly
+		if endpoint.User != "" {
+			return nil, nil
+		}
+
+		if token, ok := os.LookupEnv(GitTokenEnv); ok && token != "" && tokenAllowed(endpoint) {
+			username := os.Getenv(GitUsernameEnv)
+			if username == "" {
+				username = defaultGitUsername
+			}
+
+			return &http.BasicAuth{Username: username, Password: token}, nil
+		}
+
+		login, password, err := netrcCredentials(endpoint.Host)
+		if err != nil {
+			return nil, err
+		}
+
+		if login != "" || password != "" {
+			return &http.BasicAuth{Username: login, Password: password}, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// tokenAllowed returns whether the token may be sent to the endpoint, which
+// requires HTTPS and, if configured, one of the hosts of GONUT_GIT_TOKEN_HOST
+func tokenAllowed(endpoint *transport.Endpoint) bool {
+	if endpoint.Protocol != "https" {
+		return false
+	}
+
+	hosts := strings.TrimSpace(os.Getenv(GitTokenHostEnv))
+	if hosts == "" {
+		return true
+	}
+
+	for _, host := range strings.Split(hosts, ",") {
+		if strings.EqualFold(strings.TrimSpace(host), endpoint.Host) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// netrcCredentials returns the login and password for the given host from the
+// netrc file (NETRC environment variable or ~/.netrc), the default entry is
+// used in case there is no entry for the host
+func netrcCredentials(host string) (string, string, error) {
+	path, ok := os.LookupEnv("NETRC")
+	if !ok {
+		path = filepath.Join(HomeDir(), ".netrc")
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", "", nil
+		}
+
+		return "", "", err
+	}
+	defer file.Close()
+
+	var (
+		tokens  []string
+		scanner = bufio.NewScanner(file)
+		inMacro bool
+	)
+
+	// Macro definitions end with an empty line and are skipped
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case inMacro:
+			inMacro = line != ""
+			continue
+
+		case strings.HasPrefix(line, "macdef"):
+			inMacro = true
+			continue
+
+		case strings.HasPrefix(line, "#"):
+			continue
+		}
+
+		tokens = append(tokens, strings.Fields(line)...)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return "", "", err
+	}
+
+	type entry struct{ login, password string }
+
+	// The default entry is stored using an empty machine name, the first
+	// entry wins in case a machine is listed more than once
+	var (
+		entries = map[string]*entry{}
+		current *entry
+	)
+
+	for i := 0; i < len(tokens); i++ {
+		if tokens[i] == "default" {
+			current = &entry{}
+			if _, ok := entries[""]; !ok {
+				entries[""] = current
+			}
+
+			continue
+		}
+
+		if i+1 >= len(tokens) {
+			break
+		}
+
+		key, value := tokens[i], tokens[i+1]
+		i++
+
+		switch {
+		case key == "machine":
+			current = &entry{}
+			if _, ok := entries[value]; !ok {
+				entries[value] = current
+			}
+
+		case key == "login" && current != nil:
+			current.login = value
+
+		case key == "password" && current != nil:
+			current.password = value
+		}
+	}
+
+	for _, machine := range []string{host, ""} {
+		if entry, ok := entries[machine]; ok {
+			return entry.login, entry.password, nil
+		}
+	}
+
+	return "", "", nil
+}
+
+// isAuthError returns whether the error was caused by missing or rejected
+// credentials
+func isAuthError(err error) bool {
+	return errors.Is(err, transport.ErrAuthenticationRequired) ||
+		errors.Is(err, transport.ErrAuthorizationFailed) ||
+		strings.Contains(err.Error(), "unable to authenticate")
+}
+
+// authError explains how to configure credentials for the git repository
+func authError(url string, err error) error {
+	var details *nok.ErrorWithDetails
+	if errors.As(err, &details) {
+		return err
+	}
+
+	return nok.Errorf(
+		fmt.Sprintf("failed to authenticate against git repository %s", RedactURL(url)),
+		"%v\n\nFor HTTPS URLs, set a token in %s (and optionally %s and %s) or add the host to your ~/.netrc file. For SSH URLs, make sure the SSH agent has the key loaded, or set the key file in %s (and %s if it is encrypted).",
+		err, GitTokenEnv, GitUsernameEnv, GitTokenHostEnv, SSHKeyEnv, SSHKeyPassphraseEnv,
+	)
+}
+
+// RedactURL removes the password from the given URL, if there is one, so
+// that credentials do not end up in logs
+func RedactURL(url string) string {
+	endpoint, err := transport.NewEndpoint(url)
+	if err != nil || endpoint.Password == "" {
+		return url
+	}
+
+	endpoint.Password = "xxxxx"
+	return endpoint.String()
+}
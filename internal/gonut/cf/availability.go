@@ -0,0 +1,137 @@
+// Copyright © 2019 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cf
+
+import (
+	"sort"
+
+	"github.com/homeport/gonut/internal/gonut/nok"
+)
+
+// Availability describes whether the buildpack a sample app relies on is
+// installed on the current target, and on which of the stacks it can be used.
+// Sample apps without a buildpack rely on the buildpack detection, so the
+// buildpack settings do not apply to them.
+type Availability struct {
+	App       string   `json:"app" yaml:"app"`
+	Buildpack string   `json:"buildpack" yaml:"buildpack"`
+	Installed bool     `json:"installed" yaml:"installed"`
+	Enabled   bool     `json:"enabled" yaml:"enabled"`
+	Locked    bool     `json:"locked" yaml:"locked"`
+	Stacks    []string `json:"stacks" yaml:"stacks"`
+	Available []string `json:"available" yaml:"available"`
+}
+
+// Pushable returns true if the buildpack is enabled and can be used on at
+// least one of the installed stacks
+func (availability Availability) Pushable() bool {
+	return (availability.AutoDetect() || availability.Enabled) && len(availability.Available) > 0
+}
+
+// AutoDetect returns true if the sample app has no buildpack and relies on
+// the buildpack detection
+func (availability Availability) AutoDetect() bool {
+	return len(availability.Buildpack) == 0
+}
+
+// GetBuildpacks returns all buildpacks installed on the current target
+func GetBuildpacks() ([]BuildpackDetails, error) {
+	if !isLoggedIn() {
+		return nil, nok.Errorf(
+			"failed to get buildpacks",
+			"session is not logged into a Cloud Foundry environment",
+		)
+	}
+
+	return getBuildpacks()
+}
+
+// CheckAvailability matches the buildpack of a sample app against the list
+// of installed buildpacks and stacks. Buildpacks without a stack can be used
+// with every installed stack, just like sample apps without a buildpack.
+func CheckAvailability(app string, buildpack string, buildpacks []BuildpackDetails, stackNames []string) Availability {
+	result := Availability{
+		App:       app,
+		Buildpack: buildpack,
+		Stacks:    []string{},
+		Available: []string{},
+	}
+
+	if result.AutoDetect() {
+		result.Stacks = append(result.Stacks, stackNames...)
+		result.Available = append(result.Available, stackNames...)
+		sort.Strings(result.Stacks)
+		sort.Strings(result.Available)
+		return result
+	}
+
+	supported := map[string]struct{}{}
+	available := map[string]struct{}{}
+	for _, details := range buildpacks {
+		if details.Entity.Name != buildpack {
+			continue
+		}
+
+		result.Installed = true
+		result.Enabled = result.Enabled || details.Entity.Enabled
+		result.Locked = result.Locked || details.Entity.Locked
+
+		stack, _ := details.Entity.Stack.(string)
+		if len(stack) > 0 {
+			supported[stack] = struct{}{}
+			if details.Entity.Enabled && contains(stackNames, stack) {
+				available[stack] = struct{}{}
+			}
+
+			continue
+		}
+
+		for _, name := range stackNames {
+			supported[name] = struct{}{}
+			if details.Entity.Enabled {
+				available[name] = struct{}{}
+			}
+		}
+	}
+
+	for stack := range supported {
+		result.Stacks = append(result.Stacks, stack)
+	}
+
+	for stack := range available {
+		result.Available = append(result.Available, stack)
+	}
+
+	sort.Strings(result.Stacks)
+	sort.Strings(result.Available)
+
+	return result
+}
+
+func contains(list []string, value string) bool {
+	for _, entry := range list {
+		if entry == value {
+			return true
+		}
+	}
+
+	return false
+}
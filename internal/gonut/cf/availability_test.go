@@ -0,0 +1,101 @@
+// Copyright © 2019 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cf_test
+
+import (
+	"encoding/json"
+	"os"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/homeport/gonut/internal/gonut/cf"
+)
+
+var _ = Describe("Sample app availability", func() {
+	var (
+		buildpacks []BuildpackDetails
+		stackNames = []string{"cflinuxfs3", "cflinuxfs4"}
+	)
+
+	BeforeEach(func() {
+		data, err := os.ReadFile("../../../assets/test/cf-curl/v2/buildpacks/buildpacks-page.json")
+		Expect(err).ToNot(HaveOccurred())
+
+		var page BuildpackPage
+		Expect(json.Unmarshal(data, &page)).ToNot(HaveOccurred())
+		buildpacks = page.Resources
+	})
+
+	It("should combine all stacks of a buildpack that is installed more than once", func() {
+		availability := CheckAvailability("Go", "go_buildpack", buildpacks, stackNames)
+		Expect(availability.Installed).To(BeTrue())
+		Expect(availability.Enabled).To(BeTrue())
+		Expect(availability.Locked).To(BeFalse())
+		Expect(availability.Stacks).To(Equal([]string{"cflinuxfs3", "cflinuxfs4"}))
+		Expect(availability.Available).To(Equal([]string{"cflinuxfs3", "cflinuxfs4"}))
+		Expect(availability.Pushable()).To(BeTrue())
+	})
+
+	It("should treat a buildpack without a stack as usable with every installed stack", func() {
+		availability := CheckAvailability("Python", "python_buildpack", buildpacks, stackNames)
+		Expect(availability.Stacks).To(Equal(stackNames))
+		Expect(availability.Available).To(Equal(stackNames))
+	})
+
+	It("should not list stacks of disabled buildpacks as available", func() {
+		availability := CheckAvailability("PHP", "php_buildpack", buildpacks, stackNames)
+		Expect(availability.Installed).To(BeTrue())
+		Expect(availability.Enabled).To(BeFalse())
+		Expect(availability.Stacks).To(Equal([]string{"cflinuxfs3"}))
+		Expect(availability.Available).To(BeEmpty())
+		Expect(availability.Pushable()).To(BeFalse())
+	})
+
+	It("should not list stacks as available that are not installed", func() {
+		availability := CheckAvailability("Ruby", "ruby_buildpack", buildpacks, stackNames)
+		Expect(availability.Locked).To(BeTrue())
+		Expect(availability.Stacks).To(Equal([]string{"cflinuxfs2"}))
+		Expect(availability.Available).To(BeEmpty())
+		Expect(availability.Pushable()).To(BeFalse())
+	})
+
+	It("should report buildpacks that are not installed", func() {
+		availability := CheckAvailability("Swift", "swift_buildpack", buildpacks, stackNames)
+		Expect(availability.Installed).To(BeFalse())
+		Expect(availability.Enabled).To(BeFalse())
+		Expect(availability.Stacks).To(BeEmpty())
+		Expect(availability.Available).To(BeEmpty())
+	})
+
+	It("should treat sample apps without a buildpack as pushable on every installed stack", func() {
+		availability := CheckAvailability("Staticfile", "", buildpacks, []string{"cflinuxfs4", "cflinuxfs3"})
+		Expect(availability.AutoDetect()).To(BeTrue())
+		Expect(availability.Stacks).To(Equal(stackNames))
+		Expect(availability.Available).To(Equal(stackNames))
+		Expect(availability.Pushable()).To(BeTrue())
+	})
+
+	It("should not treat sample apps without a buildpack as pushable without stacks", func() {
+		availability := CheckAvailability("Staticfile", "", buildpacks, nil)
+		Expect(availability.Pushable()).To(BeFalse())
+	})
+})
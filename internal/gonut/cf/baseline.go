@@ -0,0 +1,171 @@
+// Copyright © 2019 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cf
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gonvenience/neat"
+	"github.com/homeport/gonut/internal/gonut/nok"
+)
+
+// Baseline contains the phase durations of a previous push, as written by
+// the JSON output of the push command
+type Baseline struct {
+	App       string
+	Stack     string
+	Buildpack string
+	Phases    map[string]time.Duration
+}
+
+// LoadBaselines reads one or more push reports in JSON format from the file
+func LoadBaselines(path string) ([]Baseline, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var baselines []Baseline
+	decoder := json.NewDecoder(file)
+	for {
+		var data map[string]interface{}
+		if err := decoder.Decode(&data); err == io.EOF {
+			break
+
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to parse baseline report %s: %w", path, err)
+		}
+
+		baseline := Baseline{Phases: map[string]time.Duration{}}
+		baseline.App, _ = data["app"].(string)
+		baseline.Stack, _ = data["stack"].(string)
+		baseline.Buildpack, _ = data["buildpack"].(string)
+
+		for _, phase := range Phases {
+			if value, ok := data[phase.Name].(float64); ok {
+				baseline.Phases[phase.Name] = time.Duration(value)
+			}
+		}
+
+		baselines = append(baselines, baseline)
+	}
+
+	if len(baselines) == 0 {
+		return nil, fmt.Errorf("baseline report %s does not contain any push report", path)
+	}
+
+	return baselines, nil
+}
+
+// FindBaseline returns the baseline of the sample app of the report, where a
+// baseline of the same stack is preferred over the ones of other stacks
+func FindBaseline(baselines []Baseline, report *PushReport) *Baseline {
+	var result *Baseline
+	for i, baseline := range baselines {
+		if baseline.App != report.Caption {
+			continue
+		}
+
+		if baseline.Stack == report.Stack() {
+			return &baselines[i]
+		}
+
+		if result == nil {
+			result = &baselines[i]
+		}
+	}
+
+	return result
+}
+
+// ParsePercentage parses a percentage like `20%` or `20` into a number
+func ParsePercentage(text string) (float64, error) {
+	value, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(text), "%"), 64)
+	if err != nil || value < 0 {
+		return 0, fmt.Errorf("invalid percentage %q", text)
+	}
+
+	return value, nil
+}
+
+// CompareToBaseline checks every phase of the report against the baseline
+// and returns an error with a per phase comparison table in case any phase
+// took longer than the baseline plus the maximum regression in percent.
+// Phases that take less than a second in both cases are not considered,
+// since the relative change of these is mostly noise. Like the phase limits
+// of the thresholds, reports without timings for all phases are not compared.
+func CompareToBaseline(baseline Baseline, report *PushReport, maxRegression float64) error {
+	if !report.HasTimeDetails() {
+		return nil
+	}
+
+	rows := [][]string{{"phase", "baseline", "current", "change", ""}}
+
+	var regressions []string
+	for _, phase := range Phases {
+		before, ok := baseline.Phases[phase.Name]
+		if !ok {
+			continue
+		}
+
+		after := phase.Duration(*report)
+		change := 0.0
+		if before > 0 {
+			change = float64(after-before) / float64(before) * 100
+		}
+
+		verdict := "ok"
+		if change > maxRegression && (before >= time.Second || after >= time.Second) {
+			verdict = "regression"
+			regressions = append(regressions, phase.Name)
+		}
+
+		rows = append(rows, []string{
+			phase.Name,
+			before.Round(time.Millisecond).String(),
+			after.Round(time.Millisecond).String(),
+			fmt.Sprintf("%+.1f%%", change),
+			verdict,
+		})
+	}
+
+	if len(regressions) == 0 {
+		return nil
+	}
+
+	table, err := neat.Table(rows, neat.AlignRight(1, 2, 3))
+	if err != nil {
+		return err
+	}
+
+	return nok.Errorf(
+		fmt.Sprintf("push of %s regressed by more than %g%% in %s", report.Caption, maxRegression, strings.Join(regressions, ", ")),
+		"%s",
+		table,
+	)
+}
@@ -0,0 +1,152 @@
+// Copyright © 2019 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cf_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/gonvenience/neat"
+	. "github.com/homeport/gonut/internal/gonut/cf"
+	"github.com/homeport/gonut/internal/gonut/nok"
+)
+
+func mockTimedReport(caption string, phase time.Duration) *PushReport {
+	start := time.Now()
+	return &PushReport{
+		Caption:        caption,
+		InitStart:      start,
+		CreatingStart:  start.Add(phase),
+		UploadingStart: start.Add(2 * phase),
+		StagingStart:   start.Add(3 * phase),
+		StartingStart:  start.Add(4 * phase),
+		PushEnd:        start.Add(5 * phase),
+	}
+}
+
+var _ = Describe("Performance regression gate", func() {
+	var dir string
+
+	BeforeEach(func() {
+		var err error
+		dir, err = os.MkdirTemp("", "gonut-test")
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	writeBaseline := func(reports ...*PushReport) string {
+		path := filepath.Join(dir, "report.json")
+
+		var content string
+		for _, report := range reports {
+			out, err := neat.NewOutputProcessor(false, false, &neat.DefaultColorSchema).ToJSON(report.Export())
+			Expect(err).ToNot(HaveOccurred())
+			content += out + "\n"
+		}
+
+		Expect(os.WriteFile(path, []byte(content), 0644)).To(Succeed())
+		return path
+	}
+
+	Context("Loading baselines", func() {
+		It("should load the phase durations from JSON push reports", func() {
+			baselines, err := LoadBaselines(writeBaseline(mockTimedReport("Golang", 10*time.Second), mockTimedReport("Python", 5*time.Second)))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(baselines).To(HaveLen(2))
+			Expect(baselines[0].App).To(Equal("Golang"))
+			Expect(baselines[0].Phases["staging"]).To(Equal(10 * time.Second))
+
+			Expect(FindBaseline(baselines, mockTimedReport("Python", time.Second)).App).To(Equal("Python"))
+			Expect(FindBaseline(baselines, mockTimedReport("Ruby", time.Second))).To(BeNil())
+		})
+
+		It("should not use the baseline of another sample app, even if there is only one", func() {
+			baselines, err := LoadBaselines(writeBaseline(mockTimedReport("Golang", 10*time.Second)))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(FindBaseline(baselines, mockTimedReport("Golang", time.Second))).ToNot(BeNil())
+			Expect(FindBaseline(baselines, mockTimedReport("Python", time.Second))).To(BeNil())
+		})
+
+		It("should prefer the baseline with the same stack", func() {
+			baselines := []Baseline{
+				{App: "Golang", Stack: "Cloud Foundry Linux-based filesystem (cflinuxfs3)"},
+				{App: "Golang", Stack: mockTimedReport("Golang", time.Second).Stack()},
+				{App: "Python", Stack: mockTimedReport("Python", time.Second).Stack()},
+			}
+
+			Expect(FindBaseline(baselines, mockTimedReport("Golang", time.Second))).To(BeIdenticalTo(&baselines[1]))
+			Expect(FindBaseline(baselines[:1], mockTimedReport("Golang", time.Second))).To(BeIdenticalTo(&baselines[0]))
+		})
+
+		It("should fail for files without push reports", func() {
+			path := filepath.Join(dir, "empty.json")
+			Expect(os.WriteFile(path, []byte{}, 0644)).To(Succeed())
+
+			_, err := LoadBaselines(path)
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should parse percentages", func() {
+			Expect(ParsePercentage("20%")).To(Equal(20.0))
+			Expect(ParsePercentage("12.5")).To(Equal(12.5))
+
+			_, err := ParsePercentage("fast")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("Comparing reports to a baseline", func() {
+		var baseline Baseline
+
+		BeforeEach(func() {
+			baselines, err := LoadBaselines(writeBaseline(mockTimedReport("Golang", 10*time.Second)))
+			Expect(err).ToNot(HaveOccurred())
+			baseline = baselines[0]
+		})
+
+		It("should accept reports within the allowed regression", func() {
+			Expect(CompareToBaseline(baseline, mockTimedReport("Golang", 11*time.Second), 20)).To(Succeed())
+		})
+
+		It("should not compare reports without timings for all push phases", func() {
+			Expect(CompareToBaseline(baseline, &PushReport{Caption: "Golang"}, 20)).To(Succeed())
+		})
+
+		It("should fail with a per phase table for reports beyond the allowed regression", func() {
+			err := CompareToBaseline(baseline, mockTimedReport("Golang", 13*time.Second), 20)
+			Expect(err).To(HaveOccurred())
+
+			var details *nok.ErrorWithDetails
+			Expect(errors.As(err, &details)).To(BeTrue())
+			Expect(details.Caption).To(ContainSubstring("staging"))
+			Expect(details.Details).To(ContainSubstring("+30.0%"))
+			Expect(details.Details).To(ContainSubstring("regression"))
+		})
+	})
+})
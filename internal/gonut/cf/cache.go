@@ -0,0 +1,352 @@
+// Copyright © 2019 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cf
+
+import (
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+
+	"github.com/homeport/gonut/internal/gonut/nok"
+)
+
+// CacheEntry describes the local copy of a git sample app source
+type CacheEntry struct {
+	Path     string
+	URL      string
+	Commit   string
+	Size     int64
+	LastUsed time.Time
+
+	// Broken is set in case the local copy cannot be read as a git repository
+	Broken bool
+}
+
+// CacheDir returns the directory that contains the local copies of git
+// sample app sources
+func CacheDir() string {
+	return filepath.Join(HomeDir(), ".gonut")
+}
+
+// CachePath returns the location of the local copy of the git repository
+// with the given host and path. Relative path elements are rejected, so that
+// the location is always a sub-directory of the cache directory.
+//
+// Example: ~/.gonut/github.com/cloudfoundry/cf-acceptance-tests
+func CachePath(host string, path string) (string, error) {
+	if host == "" || host == "." || host == ".." || strings.ContainsAny(host, `/\`) {
+		return "", fmt.Errorf("invalid repository host %q", host)
+	}
+
+	segments := []string{CacheDir(), host}
+	for _, segment := range strings.Split(path, "/") {
+		switch segment {
+		case "":
+			continue
+
+		case ".", "..":
+			return "", fmt.Errorf("invalid repository path %q, relative path elements are not supported", path)
+		}
+
+		segments = append(segments, segment)
+	}
+
+	if len(segments) == 2 {
+		return "", fmt.Errorf("repository path of %s is missing", host)
+	}
+
+	location := filepath.Join(segments...)
+	if err := checkCachePath(CacheDir(), location); err != nil {
+		return "", err
+	}
+
+	return location, nil
+}
+
+// checkCachePath makes sure that the location is strictly inside of the
+// cache directory and not part of the directory of user-defined sample apps
+func checkCachePath(dir string, location string) error {
+	dir, err := resolveExisting(dir)
+	if err != nil {
+		return err
+	}
+
+	location, err = resolveExisting(location)
+	if err != nil {
+		return err
+	}
+
+	rel, err := filepath.Rel(dir, location)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return fmt.Errorf("location %s is not inside of the cache directory %s", location, dir)
+	}
+
+	if strings.SplitN(rel, string(filepath.Separator), 2)[0] == filepath.Base(RegistryPath()) {
+		return fmt.Errorf("location %s is part of the user-defined sample apps directory", location)
+	}
+
+	return nil
+}
+
+// resolveExisting returns the absolute path with all symbolic links of the
+// longest existing part of the path evaluated, the non-existing rest of the
+// path is appended as is
+func resolveExisting(path string) (string, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+
+	var rest []string
+	for {
+		if resolved, err := filepath.EvalSymlinks(path); err == nil {
+			return filepath.Join(append([]string{resolved}, rest...)...), nil
+
+		} else if !os.IsNotExist(err) {
+			return "", err
+		}
+
+		parent := filepath.Dir(path)
+		if parent == path {
+			return filepath.Join(append([]string{path}, rest...)...), nil
+		}
+
+		rest = append([]string{filepath.Base(path)}, rest...)
+		path = parent
+	}
+}
+
+// touchCache marks the local copy at the given location as used
+func touchCache(location string) error {
+	now := time.Now()
+	return os.Chtimes(filepath.Join(location, git.GitDirName), now, now)
+}
+
+// ListCache returns the local copies of git sample app sources in the given
+// cache directory, the directory of user-defined sample apps is skipped
+func ListCache(dir string) ([]CacheEntry, error) {
+	var entries []CacheEntry
+
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && path == dir {
+				return filepath.SkipDir
+			}
+
+			return err
+		}
+
+		if !d.IsDir() {
+			return nil
+		}
+
+		if path == filepath.Join(dir, filepath.Base(RegistryPath())) {
+			return filepath.SkipDir
+		}
+
+		if info, err := os.Stat(filepath.Join(path, git.GitDirName)); err == nil && info.IsDir() {
+			entry, err := cacheEntry(path)
+			if err != nil {
+				return err
+			}
+
+			entries = append(entries, entry)
+			return filepath.SkipDir
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Path < entries[j].Path
+	})
+
+	return entries, nil
+}
+
+func cacheEntry(path string) (CacheEntry, error) {
+	entry := CacheEntry{Path: path}
+
+	info, err := os.Stat(filepath.Join(path, git.GitDirName))
+	if err != nil {
+		return entry, err
+	}
+
+	entry.LastUsed = info.ModTime()
+
+	entry.Size, err = directorySize(path)
+	if err != nil {
+		return entry, err
+	}
+
+	repo, err := git.PlainOpen(path)
+	if err != nil {
+		entry.Broken = true
+		return entry, nil
+	}
+
+	if remote, err := repo.Remote(git.DefaultRemoteName); err == nil && len(remote.Config().URLs) > 0 {
+		entry.URL = RedactURL(remote.Config().URLs[0])
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		entry.Broken = true
+		return entry, nil
+	}
+
+	if _, err := repo.CommitObject(head.Hash()); err != nil {
+		entry.Broken = true
+		return entry, nil
+	}
+
+	entry.Commit = head.Hash().String()
+	return entry, nil
+}
+
+func directorySize(path string) (int64, error) {
+	var size int64
+	err := filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.Type().IsRegular() {
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+
+			size += info.Size()
+		}
+
+		return nil
+	})
+
+	return size, err
+}
+
+// PruneCache removes the local copies in the given cache directory that were
+// last used before the given time, and returns the removed entries
+func PruneCache(dir string, before time.Time) ([]CacheEntry, error) {
+	entries, err := ListCache(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var removed []CacheEntry
+	for _, entry := range entries {
+		if !entry.LastUsed.Before(before) {
+			continue
+		}
+
+		if err := removeCacheEntry(dir, entry.Path); err != nil {
+			return removed, err
+		}
+
+		removed = append(removed, entry)
+	}
+
+	return removed, nil
+}
+
+// removeCacheEntry removes the local copy and its parent directories up to
+// the cache directory, as long as they are empty
+func removeCacheEntry(dir string, path string) error {
+	if err := os.RemoveAll(path); err != nil {
+		return err
+	}
+
+	for parent := filepath.Dir(path); parent != dir && parent != filepath.Dir(parent); parent = filepath.Dir(parent) {
+		if err := os.Remove(parent); err != nil {
+			break // not empty
+		}
+	}
+
+	return nil
+}
+
+// checkCacheEntry makes sure that the location can be removed safely, which is
+// the case for local copies of git repositories inside of the cache directory,
+// or for locations in the cache directory that do not exist yet
+func checkCacheEntry(dir string, location string) error {
+	if err := checkCachePath(dir, location); err != nil {
+		return err
+	}
+
+	if _, err := os.Lstat(location); os.IsNotExist(err) {
+		return nil
+	}
+
+	if info, err := os.Stat(filepath.Join(location, git.GitDirName)); err != nil || !info.IsDir() {
+		return fmt.Errorf("location %s exists, but is not a local copy of a git repository", location)
+	}
+
+	return nil
+}
+
+// RefreshSampleAppRef removes the local copy of the git repository and clones
+// it again, which recovers from corrupt local copies. The SHA of the checked
+// out commit is returned.
+func RefreshSampleAppRef(rootURL string, ref string, localPath string) (string, error) {
+	u, err := url.Parse(rootURL)
+	if err != nil {
+		return "", err
+	}
+
+	if u.Scheme == "file" {
+		return "", nok.Errorf(
+			fmt.Sprintf("failed to refresh %s", rootURL),
+			"sample apps from file URLs are read directly and are not cached",
+		)
+	}
+
+	if archiveFormat(u.Path) != "" {
+		return "", nok.Errorf(
+			fmt.Sprintf("failed to refresh %s", RedactURL(rootURL)),
+			"sample app archives are downloaded on every push and are not cached",
+		)
+	}
+
+	if err := checkCacheEntry(CacheDir(), localPath); err != nil {
+		return "", nok.Errorf(
+			fmt.Sprintf("failed to refresh %s", RedactURL(rootURL)),
+			err.Error(),
+		)
+	}
+
+	if err := os.RemoveAll(localPath); err != nil {
+		return "", err
+	}
+
+	return cloneOrPull(localPath, rootURL, ref)
+}
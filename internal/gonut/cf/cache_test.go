@@ -0,0 +1,248 @@
+// Copyright © 2019 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cf
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Sample App Cache", func() {
+	var (
+		tmp    string
+		source string
+		dir    string
+		sha    string
+		home   string
+	)
+
+	clone := func(name string) string {
+		location := filepath.Join(dir, "example.org", name)
+		_, err := cloneOrPull(location, "file://"+source, "")
+		Expect(err).ToNot(HaveOccurred())
+		return location
+	}
+
+	lastUsed := func(location string, when time.Time) {
+		Expect(os.Chtimes(filepath.Join(location, ".git"), when, when)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		var err error
+		tmp, err = os.MkdirTemp("", "gonut-cache")
+		Expect(err).ToNot(HaveOccurred())
+
+		source = filepath.Join(tmp, "source")
+		dir = filepath.Join(tmp, ".gonut")
+
+		home = os.Getenv("HOME")
+		os.Setenv("HOME", tmp)
+
+		repo, err := git.PlainInit(source, false)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(os.WriteFile(filepath.Join(source, "version.txt"), []byte("v1"), 0644)).To(Succeed())
+		w, err := repo.Worktree()
+		Expect(err).ToNot(HaveOccurred())
+		_, err = w.Add("version.txt")
+		Expect(err).ToNot(HaveOccurred())
+		hash, err := w.Commit("v1", &git.CommitOptions{Author: &object.Signature{Name: "gonut", Email: "gonut@example.org", When: time.Now()}})
+		Expect(err).ToNot(HaveOccurred())
+		sha = hash.String()
+	})
+
+	AfterEach(func() {
+		os.Setenv("HOME", home)
+		os.RemoveAll(tmp)
+	})
+
+	Context("listing local copies", func() {
+		It("should return no entries when there is no cache directory", func() {
+			entries, err := ListCache(dir)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(entries).To(BeEmpty())
+		})
+
+		It("should list local copies with their URL, commit, size, and last usage", func() {
+			location := clone("repo")
+
+			entries, err := ListCache(dir)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(entries).To(HaveLen(1))
+			Expect(entries[0].Path).To(Equal(location))
+			Expect(entries[0].URL).To(Equal("file://" + source))
+			Expect(entries[0].Commit).To(Equal(sha))
+			Expect(entries[0].Size).To(BeNumerically(">", 0))
+			Expect(entries[0].LastUsed).To(BeTemporally("~", time.Now(), time.Minute))
+			Expect(entries[0].Broken).To(BeFalse())
+		})
+
+		It("should skip the directory of user-defined sample apps", func() {
+			_, err := git.PlainInit(filepath.Join(dir, "apps", "mine"), false)
+			Expect(err).ToNot(HaveOccurred())
+
+			entries, err := ListCache(dir)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(entries).To(BeEmpty())
+		})
+
+		It("should mark local copies that are no valid git repository as broken", func() {
+			location := clone("repo")
+			Expect(os.RemoveAll(filepath.Join(location, ".git", "objects"))).To(Succeed())
+
+			entries, err := ListCache(dir)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(entries).To(HaveLen(1))
+			Expect(entries[0].Broken).To(BeTrue())
+		})
+	})
+
+	Context("pruning local copies", func() {
+		It("should only remove local copies that were not used recently", func() {
+			old := clone("old")
+			recent := clone("recent")
+			lastUsed(old, time.Now().Add(-48*time.Hour))
+
+			removed, err := PruneCache(dir, time.Now().Add(-24*time.Hour))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(removed).To(HaveLen(1))
+			Expect(removed[0].Path).To(Equal(old))
+
+			Expect(old).ToNot(BeADirectory())
+			Expect(recent).To(BeADirectory())
+		})
+
+		It("should remove parent directories that are empty afterwards", func() {
+			old := clone("old")
+			lastUsed(old, time.Now().Add(-48*time.Hour))
+
+			_, err := PruneCache(dir, time.Now().Add(-24*time.Hour))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(filepath.Dir(old)).ToNot(BeADirectory())
+			Expect(dir).To(BeADirectory())
+		})
+
+		It("should update the last usage when the local copy is used again", func() {
+			old := clone("old")
+			lastUsed(old, time.Now().Add(-48*time.Hour))
+			clone("old")
+
+			removed, err := PruneCache(dir, time.Now().Add(-24*time.Hour))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(removed).To(BeEmpty())
+		})
+	})
+
+	Context("refreshing local copies", func() {
+		It("should recover from a broken local copy", func() {
+			location := clone("repo")
+			Expect(os.RemoveAll(filepath.Join(location, ".git", "objects"))).To(Succeed())
+
+			_, _, err := LoadSampleAppRef(source, "", "", location)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("gonut cache refresh"))
+
+			commit, err := RefreshSampleAppRef(source, "", location)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(commit).To(Equal(sha))
+			Expect(os.ReadFile(filepath.Join(location, "version.txt"))).To(Equal([]byte("v1")))
+		})
+
+		It("should not refresh sample apps from file URLs", func() {
+			_, err := RefreshSampleAppRef("file://"+source, "", filepath.Join(dir, "repo"))
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should refuse to remove locations outside of the cache directory", func() {
+			clone("repo")
+
+			for _, location := range []string{tmp, dir, filepath.Join(dir, ".."), source} {
+				_, err := RefreshSampleAppRef(source, "", location)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("not inside of the cache directory"))
+				Expect(location).To(BeADirectory())
+			}
+
+			Expect(filepath.Join(dir, "example.org", "repo", ".git")).To(BeADirectory())
+		})
+
+		It("should refuse to remove directories that are no local copy of a git repository", func() {
+			clone("repo")
+
+			_, err := RefreshSampleAppRef(source, "", filepath.Join(dir, "example.org"))
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("not a local copy of a git repository"))
+			Expect(filepath.Join(dir, "example.org", "repo", ".git")).To(BeADirectory())
+		})
+
+		It("should refuse to remove user-defined sample apps", func() {
+			app := filepath.Join(RegistryPath(), "music")
+			Expect(os.MkdirAll(filepath.Join(app, ".git"), 0755)).To(Succeed())
+
+			_, err := RefreshSampleAppRef(source, "", app)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("user-defined sample apps"))
+			Expect(app).To(BeADirectory())
+		})
+
+		It("should refuse to follow symbolic links out of the cache directory", func() {
+			Expect(os.MkdirAll(filepath.Join(dir, "example.org"), 0755)).To(Succeed())
+			Expect(os.Symlink(tmp, filepath.Join(dir, "example.org", "link"))).To(Succeed())
+
+			_, err := RefreshSampleAppRef(source, "", filepath.Join(dir, "example.org", "link", "source"))
+			Expect(err).To(HaveOccurred())
+			Expect(source).To(BeADirectory())
+		})
+
+		It("should allow locations in the cache directory that do not exist yet", func() {
+			Expect(checkCacheEntry(dir, filepath.Join(dir, "example.org", "new"))).To(Succeed())
+		})
+	})
+
+	Context("locating local copies", func() {
+		It("should place local copies in sub-directories of the cache directory", func() {
+			Expect(CachePath("github.com", "/homeport/gonut")).To(Equal(filepath.Join(dir, "github.com", "homeport", "gonut")))
+		})
+
+		It("should reject hosts and paths that do not point into the cache directory", func() {
+			for _, hostAndPath := range [][2]string{
+				{"..", ""},
+				{".", "/repo"},
+				{"", "/repo"},
+				{"x", "/../.."},
+				{"x", "/repo/../.."},
+				{"x", "/./repo"},
+				{"github.com", ""},
+				{"github.com", "/"},
+				{"apps", "/music"},
+			} {
+				_, err := CachePath(hostAndPath[0], hostAndPath[1])
+				Expect(err).To(HaveOccurred(), "host %q and path %q", hostAndPath[0], hostAndPath[1])
+			}
+		})
+	})
+})
@@ -32,6 +32,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/gonvenience/wait"
@@ -39,8 +40,12 @@ import (
 	"github.com/homeport/pina-golada/pkg/files"
 )
 
+// PostPushCheck is an additional verification step that is run against the
+// pushed application before it is (optionally) deleted again
+type PostPushCheck func(updates chan string, appName string, report *PushReport) error
+
 // PushApp performs a Cloud Foundry CLI based push operation
-func PushApp(caption string, appName string, directory files.Directory, flags []string, cleanupSetting AppCleanupSetting, noPingSetting bool) (*PushReport, error) {
+func PushApp(caption string, appName string, directory files.Directory, flags []string, cleanupSetting AppCleanupSetting, noPingSetting bool, checks ...PostPushCheck) (*PushReport, error) {
 	if !isLoggedIn() {
 		return nil, nok.Errorf(
 			fmt.Sprintf("failed to push application %s to Cloud Foundry", appName),
@@ -56,9 +61,18 @@ func PushApp(caption string, appName string, directory files.Directory, flags []
 	}
 
 	report := PushReport{
+		Caption: caption,
 		AppName: appName,
 	}
 
+	// Keep track of the amount of files to be uploaded, since it affects the
+	// upload time
+	report.Files, report.Bytes = DirectoryStats(directory)
+
+	// Keep track of all Cloud Foundry CLI calls made during the push
+	recorder := startRecording()
+	defer func() { report.Invocations = recorder.stop() }()
+
 	err := runWithTempDir(func(path string) error {
 		// Changed during each step of the verification process
 		step := "Ramp-up"
@@ -151,29 +165,15 @@ func PushApp(caption string, appName string, directory files.Directory, flags []
 		// If pinging is not disabled, ping the pushed app to
 		// determine its statuscode.
 		if !noPingSetting {
-			// Get public URL of application
-			appRoute, err := getAppRoute(appName)
-			if err != nil {
-				return nok.Errorf(
-					fmt.Sprintf("failed to get url of application %s from Cloud Foundry", appName),
-					err.Error(),
-				)
+			if err := pingApp(appName, http.StatusOK, &report); err != nil {
+				return err
 			}
+		}
 
-			if statusCode, err := getAppStatusCode(appRoute); err == nil {
-				if statusCode != http.StatusOK {
-					return nok.Errorf(
-						fmt.Sprintf("application %s returned a non-ok statuscode %d", appName, statusCode),
-						"The application did not return the statuscode 200. Please try to push the same sample application again.",
-					)
-				}
-				report.StatusCode = statusCode
-
-			} else {
-				return nok.Errorf(
-					fmt.Sprintf("unable to ping application %s with route %s", appName, appRoute),
-					err.Error(),
-				)
+		// Run additional checks against the pushed application
+		for _, check := range checks {
+			if err := check(updates, appName, &report); err != nil {
+				return err
 			}
 		}
 
@@ -311,6 +311,82 @@ func runWithTempDir(f func(path string) error) error {
 	return f(dir)
 }
 
+// TargetOrgAndSpace changes the org and space targeted by the cf CLI, where an
+// empty org or space keeps the current one
+func TargetOrgAndSpace(org string, space string) error {
+	args := []string{"target"}
+	if org != "" {
+		args = append(args, "-o", org)
+	}
+
+	if space != "" {
+		args = append(args, "-s", space)
+	}
+
+	if output, err := cf(nil, args...); err != nil {
+		return nok.Errorf(
+			"failed to target org and space",
+			"cf %s failed: %v\n\n%s", strings.Join(args, " "), err, output,
+		)
+	}
+
+	return nil
+}
+
+// IsolateCFHome copies the cf CLI configuration into a temporary directory
+// and uses it as CF_HOME for all cf CLI calls of this process. This way,
+// targeting another org and space does not change the target of the user.
+// The returned function removes the temporary directory and restores the
+// previous CF_HOME.
+func IsolateCFHome() (func(), error) {
+	source := os.Getenv("CF_HOME")
+	if source == "" {
+		var err error
+		if source, err = os.UserHomeDir(); err != nil {
+			return nil, err
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(source, ".cf", "config.json"))
+	if err != nil {
+		return nil, nok.Errorf(
+			"failed to target org and space",
+			"cannot read the cf CLI configuration: %v", err,
+		)
+	}
+
+	tmp, err := os.MkdirTemp("", "gonut-cf-home")
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(filepath.Join(tmp, ".cf"), os.FileMode(0700)); err != nil {
+		os.RemoveAll(tmp)
+		return nil, err
+	}
+
+	if err := os.WriteFile(filepath.Join(tmp, ".cf", "config.json"), data, 0600); err != nil {
+		os.RemoveAll(tmp)
+		return nil, err
+	}
+
+	previous, wasSet := os.LookupEnv("CF_HOME")
+	if err := os.Setenv("CF_HOME", tmp); err != nil {
+		os.RemoveAll(tmp)
+		return nil, err
+	}
+
+	return func() {
+		if wasSet {
+			os.Setenv("CF_HOME", previous)
+		} else {
+			os.Unsetenv("CF_HOME")
+		}
+
+		os.RemoveAll(tmp)
+	}, nil
+}
+
 func isLoggedIn() bool {
 	config, err := getCloudFoundryConfig()
 	if err != nil {
@@ -335,9 +411,13 @@ func getOrgAndSpaceNamesFromConfig() (string, string, error) {
 }
 
 func getCloudFoundryConfig() (*CloudFoundryConfig, error) {
-	path, err := os.UserHomeDir()
-	if err != nil {
-		return nil, err
+	// Same as the cf CLI, use CF_HOME instead of the home directory if set
+	path := os.Getenv("CF_HOME")
+	if path == "" {
+		var err error
+		if path, err = os.UserHomeDir(); err != nil {
+			return nil, err
+		}
 	}
 
 	data, err := os.ReadFile(filepath.Join(path, ".cf", "config.json"))
@@ -373,6 +453,51 @@ func getAppRoute(appName string) (string, error) {
 	return fmt.Sprintf("http://%s.%s", host, domain), nil
 }
 
+// PingCheck returns a post push check that pings the application and expects
+// the given statuscode, for apps that do not respond with 200 on purpose
+func PingCheck(expectedStatusCode int) PostPushCheck {
+	return func(updates chan string, appName string, report *PushReport) error {
+		return pingApp(appName, expectedStatusCode, report)
+	}
+}
+
+// pingApp sends a request to the route of the application and verifies the
+// statuscode of the response
+func pingApp(appName string, expectedStatusCode int, report *PushReport) error {
+	// Get public URL of application
+	appRoute, err := getAppRoute(appName)
+	if err != nil {
+		return nok.Errorf(
+			fmt.Sprintf("failed to get url of application %s from Cloud Foundry", appName),
+			err.Error(),
+		)
+	}
+
+	statusCode, err := getAppStatusCode(appRoute)
+	if err != nil {
+		return nok.Errorf(
+			fmt.Sprintf("unable to ping application %s with route %s", appName, appRoute),
+			err.Error(),
+		)
+	}
+
+	if statusCode != expectedStatusCode {
+		caption := fmt.Sprintf("application %s returned statuscode %d instead of %d", appName, statusCode, expectedStatusCode)
+		if expectedStatusCode == http.StatusOK {
+			caption = fmt.Sprintf("application %s returned a non-ok statuscode %d", appName, statusCode)
+		}
+
+		return nok.Errorf(
+			caption,
+			"The application did not return the statuscode %d. Please try to push the same sample application again.",
+			expectedStatusCode,
+		)
+	}
+
+	report.StatusCode = statusCode
+	return nil
+}
+
 // getAppStatusCode sends a GET request to the given URL
 // and returns the statuscode.
 func getAppStatusCode(appRoute string) (int, error) {
@@ -520,10 +645,63 @@ func getHostAndDomain(appName string) (string, string, error) {
 	return host, domain, nil
 }
 
+// Invocation contains the details of one Cloud Foundry CLI call
+type Invocation struct {
+	Args  []string
+	Start time.Time
+	End   time.Time
+	Err   error
+}
+
+// invocationRecorder collects the Cloud Foundry CLI calls made while it is
+// active, calls outside of a recording are not kept at all
+type invocationRecorder struct {
+	invocations []Invocation
+}
+
+var (
+	recorders      []*invocationRecorder
+	recordersMutex sync.Mutex
+)
+
+func startRecording() *invocationRecorder {
+	recordersMutex.Lock()
+	defer recordersMutex.Unlock()
+
+	recorder := &invocationRecorder{}
+	recorders = append(recorders, recorder)
+	return recorder
+}
+
+// stop ends the recording and returns the recorded calls
+func (recorder *invocationRecorder) stop() []Invocation {
+	recordersMutex.Lock()
+	defer recordersMutex.Unlock()
+
+	for i, candidate := range recorders {
+		if candidate == recorder {
+			recorders = append(recorders[:i], recorders[i+1:]...)
+			break
+		}
+	}
+
+	return recorder.invocations
+}
+
+func recordInvocation(invocation Invocation) {
+	recordersMutex.Lock()
+	defer recordersMutex.Unlock()
+
+	for _, recorder := range recorders {
+		recorder.invocations = append(recorder.invocations, invocation)
+	}
+}
+
 func cf(updates chan string, args ...string) (string, error) {
 	var (
-		buf bytes.Buffer
-		err error
+		buf   bytes.Buffer
+		err   error
+		start = time.Now()
 	)
 
 	read, write := io.Pipe()
@@ -554,6 +732,8 @@ func cf(updates chan string, args ...string) (string, error) {
 		}
 	}
 
+	recordInvocation(Invocation{Args: args, Start: start, End: time.Now(), Err: err})
+
 	return buf.String(), err
 }
 
@@ -612,6 +792,20 @@ func cfCurlAppByGUID(appGUID string) (*AppDetails, error) {
 	return &app, nil
 }
 
+func cfCurlAppInstancesByGUID(appGUID string) (map[string]InstanceDetails, error) {
+	result, err := cf(nil, "curl", fmt.Sprintf("/v2/apps/%s/instances", appGUID))
+	if err != nil {
+		return nil, err
+	}
+
+	var instances map[string]InstanceDetails
+	if err := json.Unmarshal([]byte(result), &instances); err != nil {
+		return nil, err
+	}
+
+	return instances, nil
+}
+
 func cfCurlBuildpackByGUID(buildpackGUID string) (*BuildpackDetails, error) {
 	result, err := cf(nil, "curl", fmt.Sprintf("/v2/buildpacks/%s", buildpackGUID))
 	if err != nil {
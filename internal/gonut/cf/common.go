@@ -61,3 +61,51 @@ func HumanReadableDuration(duration time.Duration) string {
 
 	return strings.Join(parts, " ")
 }
+
+// HumanReadableSize returns a human readable version of the size in bytes
+func HumanReadableSize(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+
+	div, exp := int64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f %ciB", float64(bytes)/float64(div), "KMGTPE"[exp])
+}
+
+// Sparkline returns a one line chart of the values using block characters
+func Sparkline(values []float64) string {
+	if len(values) == 0 {
+		return ""
+	}
+
+	ticks := []rune("▁▂▃▄▅▆▇█")
+
+	min, max := values[0], values[0]
+	for _, value := range values {
+		if value < min {
+			min = value
+		}
+
+		if value > max {
+			max = value
+		}
+	}
+
+	var result strings.Builder
+	for _, value := range values {
+		index := 0
+		if max > min {
+			index = int((value - min) / (max - min) * float64(len(ticks)-1))
+		}
+
+		result.WriteRune(ticks[index])
+	}
+
+	return result.String()
+}
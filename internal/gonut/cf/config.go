@@ -0,0 +1,127 @@
+// Copyright © 2019 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cf
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// Config contains the settings of the gonut configuration file, for example:
+//
+//	app-name-prefix: smoke
+//	target:
+//	  cf-home: ~/.cf-production
+//	  org: system
+//	  space: gonut
+//	push:
+//	  delete: on-success
+//	  no-ping: true
+//	outputs:
+//	  junit-file: /tmp/gonut.xml
+//	  webhook:
+//	  - slack=https://hooks.slack.com/services/...
+type Config struct {
+	AppNamePrefix string                 `yaml:"app-name-prefix"`
+	Target        TargetConfig           `yaml:"target"`
+	Push          map[string]interface{} `yaml:"push"`
+	Outputs       map[string]interface{} `yaml:"outputs"`
+}
+
+// TargetConfig overrides the Cloud Foundry target of the cf CLI
+type TargetConfig struct {
+	CFHome string `yaml:"cf-home"`
+	Org    string `yaml:"org"`
+	Space  string `yaml:"space"`
+}
+
+// ConfigPath returns the default location of the gonut configuration file
+func ConfigPath() string {
+	return filepath.Join(HomeDir(), ".gonut", "config.yml")
+}
+
+// LoadConfig reads the gonut configuration file
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var config Config
+	if err := yaml.UnmarshalStrict(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse configuration file %s: %w", path, err)
+	}
+
+	config.Target.CFHome = ExpandHomeDir(config.Target.CFHome)
+
+	return &config, nil
+}
+
+// Setting returns the configured values of the push or output setting with
+// the given name, which is the name of the respective command line flag
+func (config *Config) Setting(name string) ([]string, bool) {
+	for _, section := range []map[string]interface{}{config.Outputs, config.Push} {
+		value, ok := section[name]
+		if !ok || value == nil {
+			continue
+		}
+
+		if list, ok := value.([]interface{}); ok {
+			values := make([]string, len(list))
+			for i, entry := range list {
+				values[i] = fmt.Sprint(entry)
+			}
+
+			return values, true
+		}
+
+		return []string{fmt.Sprint(value)}, true
+	}
+
+	return nil, false
+}
+
+// SettingNames returns the sorted names of all push and output settings
+func (config *Config) SettingNames() []string {
+	var names []string
+	for _, section := range []map[string]interface{}{config.Push, config.Outputs} {
+		for name := range section {
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+// ExpandHomeDir replaces a leading tilde in the path with the home directory
+func ExpandHomeDir(path string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		return filepath.Join(HomeDir(), strings.TrimPrefix(path, "~"))
+	}
+
+	return path
+}
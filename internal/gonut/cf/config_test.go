@@ -0,0 +1,93 @@
+// Copyright © 2019 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cf_test
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/homeport/gonut/internal/gonut/cf"
+)
+
+var _ = Describe("Configuration file", func() {
+	Context("Loading the configuration file", func() {
+		It("should read the app name prefix and target overrides", func() {
+			config, err := LoadConfig("../../../assets/test/config/config.yml")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(config.AppNamePrefix).To(Equal("smoke"))
+			Expect(config.Target.CFHome).To(Equal(filepath.Join(HomeDir(), ".cf-production")))
+			Expect(config.Target.Org).To(Equal("system"))
+			Expect(config.Target.Space).To(Equal("gonut"))
+		})
+
+		It("should fail for unknown sections", func() {
+			_, err := LoadConfig("../../../assets/test/config/invalid.yml")
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should report a missing file as such", func() {
+			_, err := LoadConfig("../../../assets/test/config/missing.yml")
+			Expect(os.IsNotExist(err)).To(BeTrue())
+		})
+	})
+
+	Context("Push and output settings", func() {
+		var config *Config
+
+		BeforeEach(func() {
+			var err error
+			config, err = LoadConfig("../../../assets/test/config/config.yml")
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("should return scalar settings as text", func() {
+			Expect(setting(config, "delete")).To(Equal([]string{"on-success"}))
+			Expect(setting(config, "no-ping")).To(Equal([]string{"true"}))
+			Expect(setting(config, "max-total")).To(Equal([]string{"5m"}))
+			Expect(setting(config, "junit-file")).To(Equal([]string{"/tmp/gonut.xml"}))
+		})
+
+		It("should return all values of list settings", func() {
+			Expect(setting(config, "webhook")).To(Equal([]string{
+				"slack=https://hooks.slack.com/services/T0/B0/X",
+				"https://hooks.example.org/gonut",
+			}))
+		})
+
+		It("should not return settings that are not configured", func() {
+			_, ok := config.Setting("stack")
+			Expect(ok).To(BeFalse())
+		})
+
+		It("should list the names of all configured settings", func() {
+			Expect(config.SettingNames()).To(Equal([]string{"delete", "junit-file", "max-total", "no-ping", "webhook"}))
+		})
+	})
+})
+
+func setting(config *Config, name string) []string {
+	values, ok := config.Setting(name)
+	Expect(ok).To(BeTrue())
+	return values
+}
@@ -0,0 +1,85 @@
+// Copyright © 2019 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cf
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/gomega"
+)
+
+// fakeResponse is the output and exit code of one call of the fake cf binary
+type fakeResponse struct {
+	output   string
+	exitCode int
+}
+
+const fakeCFScript = `#!/bin/sh
+dir="$(dirname "$0")"
+count=$(($(cat "$dir/count") + 1))
+echo "$count" >"$dir/count"
+echo "$*" >>"$dir/calls"
+echo "$CF_HOME" >>"$dir/cf-homes"
+if [ ! -f "$dir/$count.out" ]; then
+  echo "unexpected call: $*"
+  exit 1
+fi
+cat "$dir/$count.out"
+exit "$(cat "$dir/$count.code")"
+`
+
+// installFakeCF puts a cf script in front of the PATH, which answers the calls
+// in the order of the given responses. It returns the directory of the script,
+// which contains the list of calls, and a function to restore the PATH.
+func installFakeCF(responses ...fakeResponse) (string, func()) {
+	dir, err := os.MkdirTemp("", "gonut-fake-cf")
+	Expect(err).ToNot(HaveOccurred())
+
+	Expect(os.WriteFile(filepath.Join(dir, "cf"), []byte(fakeCFScript), 0755)).To(Succeed())
+	Expect(os.WriteFile(filepath.Join(dir, "count"), []byte("0\n"), 0644)).To(Succeed())
+
+	for i, response := range responses {
+		Expect(os.WriteFile(filepath.Join(dir, fmt.Sprintf("%d.out", i+1)), []byte(response.output), 0644)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(dir, fmt.Sprintf("%d.code", i+1)), []byte(fmt.Sprintf("%d\n", response.exitCode)), 0644)).To(Succeed())
+	}
+
+	path := os.Getenv("PATH")
+	os.Setenv("PATH", dir+string(os.PathListSeparator)+path)
+
+	return dir, func() {
+		os.Setenv("PATH", path)
+		os.RemoveAll(dir)
+	}
+}
+
+// fakeCFCalls returns the arguments of all calls of the fake cf binary
+func fakeCFCalls(dir string) []string {
+	data, err := os.ReadFile(filepath.Join(dir, "calls"))
+	if os.IsNotExist(err) {
+		return nil
+	}
+
+	Expect(err).ToNot(HaveOccurred())
+	return strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+}
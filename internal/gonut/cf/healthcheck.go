@@ -0,0 +1,102 @@
+// Copyright © 2019 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cf
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/homeport/gonut/internal/gonut/nok"
+)
+
+// runningPollInterval is the time between two checks of the instance states,
+// and runningPollAttempts the number of checks before the running check fails
+var (
+	runningPollInterval = 2 * time.Second
+	runningPollAttempts = 30
+)
+
+// RunningCheck returns a post push check that verifies that all instances of
+// the pushed application reach the running state. A crashed instance fails
+// the check right away.
+func RunningCheck() PostPushCheck {
+	return func(updates chan string, appName string, report *PushReport) error {
+		appGUID, err := cfAppGUID(appName)
+		if err != nil {
+			return nok.Errorf(
+				fmt.Sprintf("failed to get instances of application %s", appName),
+				err.Error(),
+			)
+		}
+
+		var states []string
+		for attempt := 0; attempt < runningPollAttempts; attempt++ {
+			instances, err := cfCurlAppInstancesByGUID(appGUID)
+			if err != nil {
+				return nok.Errorf(
+					fmt.Sprintf("failed to get instances of application %s", appName),
+					err.Error(),
+				)
+			}
+
+			indices := make([]string, 0, len(instances))
+			for index := range instances {
+				indices = append(indices, index)
+			}
+
+			sort.Slice(indices, func(i, j int) bool {
+				a, _ := strconv.Atoi(indices[i])
+				b, _ := strconv.Atoi(indices[j])
+				return a < b
+			})
+
+			states = []string{}
+			running, crashed := len(instances) > 0, false
+			for _, index := range indices {
+				state := instances[index].State
+				states = append(states, fmt.Sprintf("instance %s: %s", index, state))
+				running = running && state == "RUNNING"
+				crashed = crashed || state == "CRASHED"
+			}
+
+			if running {
+				return nil
+			}
+
+			if crashed {
+				return nok.Errorf(
+					fmt.Sprintf("application %s crashed", appName),
+					strings.Join(states, "\n"),
+				)
+			}
+
+			time.Sleep(runningPollInterval)
+		}
+
+		return nok.Errorf(
+			fmt.Sprintf("application %s did not reach the running state", appName),
+			strings.Join(states, "\n"),
+		)
+	}
+}
@@ -0,0 +1,120 @@
+// Copyright © 2019 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cf
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/homeport/gonut/internal/gonut/nok"
+)
+
+var _ = Describe("Running check", func() {
+	var (
+		restore          []func()
+		previousInterval time.Duration
+		previousAttempts int
+	)
+
+	fake := func(responses ...fakeResponse) string {
+		dir, cleanup := installFakeCF(responses...)
+		restore = append(restore, cleanup)
+		return dir
+	}
+
+	instances := func(json string) fakeResponse {
+		return fakeResponse{output: json}
+	}
+
+	BeforeEach(func() {
+		previousInterval, previousAttempts = runningPollInterval, runningPollAttempts
+		runningPollInterval = time.Millisecond
+	})
+
+	AfterEach(func() {
+		runningPollInterval, runningPollAttempts = previousInterval, previousAttempts
+		for i := len(restore) - 1; i >= 0; i-- {
+			restore[i]()
+		}
+
+		restore = nil
+	})
+
+	It("should pass once all instances are running", func() {
+		dir := fake(
+			fakeResponse{output: "app-guid\n"},
+			instances(`{"0":{"state":"STARTING"},"1":{"state":"RUNNING"}}`),
+			instances(`{"0":{"state":"RUNNING"},"1":{"state":"RUNNING"}}`),
+		)
+
+		var report PushReport
+		Expect(RunningCheck()(nil, "gonut-app", &report)).To(Succeed())
+		Expect(fakeCFCalls(dir)).To(Equal([]string{
+			"app gonut-app --guid",
+			"curl /v2/apps/app-guid/instances",
+			"curl /v2/apps/app-guid/instances",
+		}))
+	})
+
+	It("should fail right away if an instance crashed", func() {
+		dir := fake(
+			fakeResponse{output: "app-guid\n"},
+			instances(`{"0":{"state":"RUNNING"},"1":{"state":"CRASHED"}}`),
+		)
+
+		var report PushReport
+		err := RunningCheck()(nil, "gonut-app", &report)
+		Expect(err).To(HaveOccurred())
+		Expect(err).To(BeAssignableToTypeOf(&nok.ErrorWithDetails{}))
+		Expect(err.(*nok.ErrorWithDetails).Caption).To(ContainSubstring("crashed"))
+		Expect(err.(*nok.ErrorWithDetails).Details).To(Equal("instance 0: RUNNING\ninstance 1: CRASHED"))
+		Expect(fakeCFCalls(dir)).To(HaveLen(2))
+	})
+
+	It("should fail with the last instance states if the instances do not start in time", func() {
+		runningPollAttempts = 3
+		dir := fake(
+			fakeResponse{output: "app-guid\n"},
+			instances(`{"0":{"state":"STARTING"}}`),
+			instances(`{"0":{"state":"STARTING"}}`),
+			instances(`{"0":{"state":"DOWN"}}`),
+		)
+
+		var report PushReport
+		err := RunningCheck()(nil, "gonut-app", &report)
+		Expect(err).To(HaveOccurred())
+		Expect(err.(*nok.ErrorWithDetails).Caption).To(ContainSubstring("did not reach the running state"))
+		Expect(err.(*nok.ErrorWithDetails).Details).To(Equal("instance 0: DOWN"))
+		Expect(fakeCFCalls(dir)).To(HaveLen(4))
+	})
+
+	It("should fail if the instances cannot be retrieved", func() {
+		fake(
+			fakeResponse{output: "app-guid\n"},
+			fakeResponse{output: "Request error", exitCode: 1},
+		)
+
+		var report PushReport
+		Expect(RunningCheck()(nil, "gonut-app", &report)).ToNot(Succeed())
+	})
+})
@@ -0,0 +1,179 @@
+// Copyright © 2019 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cf
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// HistoryEntry is the persisted result of one sample app push
+type HistoryEntry struct {
+	Timestamp    time.Time                `json:"timestamp"`
+	GonutVersion string                   `json:"gonutVersion"`
+	Target       string                   `json:"target"`
+	Org          string                   `json:"org"`
+	Space        string                   `json:"space"`
+	App          string                   `json:"app"`
+	Commit       string                   `json:"commit,omitempty"`
+	Files        int                      `json:"files,omitempty"`
+	Bytes        int64                    `json:"bytes,omitempty"`
+	AppName      string                   `json:"appName,omitempty"`
+	Stack        string                   `json:"stack,omitempty"`
+	Buildpack    string                   `json:"buildpack,omitempty"`
+	Lifecycle    string                   `json:"lifecycle,omitempty"`
+	HealthCheck  string                   `json:"healthCheck,omitempty"`
+	Success      bool                     `json:"success"`
+	Error        string                   `json:"error,omitempty"`
+	StatusCode   int                      `json:"statusCode,omitempty"`
+	Phases       map[string]time.Duration `json:"phases,omitempty"`
+	Total        time.Duration            `json:"total,omitempty"`
+}
+
+// NewHistoryEntry creates a history entry for the given push report, which
+// can be nil in case the push failed before the app was created
+func NewHistoryEntry(app string, report *PushReport, err error) HistoryEntry {
+	entry := HistoryEntry{
+		Timestamp: time.Now(),
+		App:       app,
+		Success:   err == nil,
+	}
+
+	if err != nil {
+		entry.Error = err.Error()
+	}
+
+	if config, configErr := getCloudFoundryConfig(); configErr == nil {
+		entry.Target = config.Target
+		entry.Org = config.OrganizationFields.Name
+		entry.Space = config.SpaceFields.Name
+	}
+
+	if report != nil {
+		entry.AppName = report.AppName
+		entry.Commit = report.Commit
+		entry.Files = report.Files
+		entry.Bytes = report.Bytes
+		entry.Stack = report.StackName()
+		entry.Buildpack = report.BuildpackName()
+		entry.Lifecycle = report.Lifecycle
+		entry.HealthCheck = report.HealthCheckType
+		entry.StatusCode = report.StatusCode
+
+		if !report.InitStart.IsZero() {
+			entry.Timestamp = report.InitStart
+		}
+
+		if err == nil {
+			entry.Total = report.ElapsedTime()
+		}
+
+		if report.HasTimeDetails() {
+			entry.Phases = map[string]time.Duration{}
+			for _, phase := range Phases {
+				entry.Phases[phase.Name] = phase.Duration(*report)
+			}
+		}
+	}
+
+	return entry
+}
+
+// SameSetup returns whether both entries are pushes of the same sample app
+// using the same settings on the same target. Failed pushes often do not know
+// the stack and buildpack, which is why these are only compared if both
+// entries have them.
+func (entry HistoryEntry) SameSetup(other HistoryEntry) bool {
+	return entry.Target == other.Target &&
+		entry.Org == other.Org &&
+		entry.Space == other.Space &&
+		entry.App == other.App &&
+		sameIfKnown(entry.Stack, other.Stack) &&
+		sameIfKnown(entry.Buildpack, other.Buildpack) &&
+		entry.Lifecycle == other.Lifecycle &&
+		entry.HealthCheck == other.HealthCheck
+}
+
+func sameIfKnown(value string, other string) bool {
+	return value == "" || other == "" || value == other
+}
+
+// HistoryPath returns the default location of the push history file
+func HistoryPath() string {
+	return filepath.Join(HomeDir(), ".gonut", "history.jsonl")
+}
+
+// AppendHistory adds the entries to the history file, one JSON document per
+// line, and creates the file if it does not exist yet
+func AppendHistory(path string, entries ...HistoryEntry) error {
+	if err := os.MkdirAll(filepath.Dir(path), os.FileMode(0755)); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	encoder := json.NewEncoder(file)
+	for _, entry := range entries {
+		if err := encoder.Encode(entry); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// LoadHistory reads all entries of the history file, a missing file is
+// treated like an empty history
+func LoadHistory(path string) ([]HistoryEntry, error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var entries []HistoryEntry
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var entry HistoryEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, scanner.Err()
+}
@@ -0,0 +1,105 @@
+// Copyright © 2019 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cf_test
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/homeport/gonut/internal/gonut/cf"
+)
+
+var _ = Describe("Push history", func() {
+	var dir string
+
+	BeforeEach(func() {
+		var err error
+		dir, err = os.MkdirTemp("", "gonut-test")
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	Context("Storing push results", func() {
+		It("should return an empty history if there is no history file", func() {
+			entries, err := LoadHistory(filepath.Join(dir, "history.jsonl"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(entries).To(BeEmpty())
+		})
+
+		It("should append entries to the history file", func() {
+			path := filepath.Join(dir, ".gonut", "history.jsonl")
+			report := createMockReport("../../../assets/test/cf-push/api-2.133.0/push-and-delete.log")
+
+			Expect(AppendHistory(path, NewHistoryEntry("Golang", report, nil))).To(Succeed())
+			Expect(AppendHistory(path, NewHistoryEntry("Python", nil, os.ErrNotExist))).To(Succeed())
+
+			entries, err := LoadHistory(path)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(entries).To(HaveLen(2))
+
+			Expect(entries[0].App).To(Equal("Golang"))
+			Expect(entries[0].AppName).To(Equal("the-app-name"))
+			Expect(entries[0].Success).To(BeTrue())
+			Expect(entries[0].Phases).To(HaveKey("staging"))
+			Expect(entries[0].Total).To(Equal(report.ElapsedTime()))
+
+			Expect(entries[1].App).To(Equal("Python"))
+			Expect(entries[1].Success).To(BeFalse())
+			Expect(entries[1].Error).To(Equal(os.ErrNotExist.Error()))
+		})
+	})
+
+	Context("Comparing push setups", func() {
+		succeeded := HistoryEntry{Target: "https://api.example.com", App: "Golang", Stack: "cflinuxfs4", Buildpack: "go_buildpack", Success: true}
+
+		It("should treat a failed push without stack and buildpack like the successful one", func() {
+			failed := HistoryEntry{Target: "https://api.example.com", App: "Golang"}
+			Expect(failed.SameSetup(succeeded)).To(BeTrue())
+			Expect(succeeded.SameSetup(failed)).To(BeTrue())
+		})
+
+		It("should differentiate pushes to different stacks", func() {
+			other := succeeded
+			other.Stack = "cflinuxfs3"
+			Expect(other.SameSetup(succeeded)).To(BeFalse())
+		})
+
+		It("should differentiate pushes with different health checks", func() {
+			other := succeeded
+			other.HealthCheck = "http"
+			Expect(other.SameSetup(succeeded)).To(BeFalse())
+		})
+	})
+
+	Context("Showing trends", func() {
+		It("should render values as sparkline", func() {
+			Expect(Sparkline([]float64{1, 8, 4.5})).To(Equal("▁█▄"))
+			Expect(Sparkline([]float64{3, 3})).To(Equal("▁▁"))
+			Expect(Sparkline(nil)).To(BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,146 @@
+// Copyright © 2019 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cf
+
+import (
+	"bytes"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/format/gitignore"
+	"github.com/homeport/pina-golada/pkg/files"
+	"github.com/homeport/pina-golada/pkg/files/paths"
+)
+
+// CFIgnoreFile is the file that lists the sample app files that are not
+// pushed, it uses the same syntax as .gitignore files
+const CFIgnoreFile = ".cfignore"
+
+// defaultIgnorePatterns are never pushed, these are version control
+// directories and macOS metadata files, like the Cloud Foundry CLI does
+var defaultIgnorePatterns = []string{".git", ".hg", ".svn", "_darcs", ".DS_Store"}
+
+// newIgnoreMatcher returns a matcher for the default ignore patterns and the
+// patterns of the given .cfignore file content
+func newIgnoreMatcher(cfignore string) gitignore.Matcher {
+	var patterns []gitignore.Pattern
+	for _, line := range append(defaultIgnorePatterns, strings.Split(cfignore, "\n")...) {
+		line = strings.TrimRight(line, " \r\t")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		patterns = append(patterns, gitignore.ParsePattern(line, nil))
+	}
+
+	return gitignore.NewMatcher(patterns)
+}
+
+// loadFromDisk loads the sample app at the given path into the in-memory
+// directory, files listed in the .cfignore file of the sample app, and version
+// control directories like .git are skipped
+func loadFromDisk(directory files.Directory, path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	if !info.IsDir() {
+		return files.LoadFromDisk(directory, path)
+	}
+
+	cfignore, err := os.ReadFile(filepath.Join(path, CFIgnoreFile))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	matcher := newIgnoreMatcher(string(cfignore))
+	directory.WithPermission(info.Mode())
+
+	return filepath.WalkDir(path, func(current string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if current == path {
+			return nil
+		}
+
+		rel, err := filepath.Rel(path, current)
+		if err != nil {
+			return err
+		}
+
+		if matcher.Match(strings.Split(filepath.ToSlash(rel), "/"), d.IsDir()) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		// Symbolic links are followed like files.LoadFromDisk does
+		info, err := os.Stat(current)
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			directory.NewDirectory(paths.Of(rel)).WithPermission(info.Mode())
+			return nil
+		}
+
+		if info.IsDir() {
+			return files.LoadFromDisk(directory.NewDirectory(paths.Of(rel)), current)
+		}
+
+		content, err := os.ReadFile(current)
+		if err != nil {
+			return err
+		}
+
+		return directory.NewFile(paths.Of(rel)).WithPermission(info.Mode()).Write(bytes.NewReader(content))
+	})
+}
+
+// DirectoryStats returns the number of files and their size in bytes
+func DirectoryStats(directory files.Directory) (count int, size int64) {
+	files.WalkFileTree(directory, func(file files.File) {
+		var counter byteCounter
+		if err := file.CopyContent(&counter); err == nil {
+			size += int64(counter)
+		}
+
+		count++
+	})
+
+	return count, size
+}
+
+// byteCounter is a writer that only counts the bytes written to it
+type byteCounter int64
+
+func (counter *byteCounter) Write(p []byte) (int, error) {
+	*counter += byteCounter(len(p))
+	return len(p), nil
+}
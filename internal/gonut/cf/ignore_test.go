@@ -0,0 +1,148 @@
+// Copyright © 2019 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cf_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"os"
+	"path/filepath"
+	"sort"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/homeport/pina-golada/pkg/files"
+
+	. "github.com/homeport/gonut/internal/gonut/cf"
+)
+
+var _ = Describe("Ignoring sample app files", func() {
+	var tmp string
+
+	writeFiles := func(root string, content map[string]string) {
+		for name, data := range content {
+			path := filepath.Join(root, filepath.FromSlash(name))
+			Expect(os.MkdirAll(filepath.Dir(path), 0755)).To(Succeed())
+			Expect(os.WriteFile(path, []byte(data), 0644)).To(Succeed())
+		}
+	}
+
+	fileNames := func(directory files.Directory) []string {
+		var result []string
+		files.WalkFileTree(directory, func(file files.File) {
+			result = append(result, file.AbsolutePath().String())
+		})
+
+		sort.Strings(result)
+		return result
+	}
+
+	content := map[string]string{
+		".cfignore":                 "# build artifacts\nnode_modules/\n*.log\n/tmp\n!keep.log\n",
+		".gitignore":                "node_modules/",
+		".git/HEAD":                 "ref: refs/heads/main",
+		".git/objects/ab/cdef":      "object",
+		"lib/.svn/entries":          "svn",
+		".DS_Store":                 "finder",
+		"index.js":                  "console.log('Hello Gonut!')",
+		"debug.log":                 "debug output",
+		"keep.log":                  "important",
+		"node_modules/left-pad/pad": "pad",
+		"tmp/cache":                 "cache",
+		"lib/tmp/helper.js":         "helper",
+		"manifest.yml":              "applications: []",
+	}
+
+	expected := []string{
+		"/.cfignore",
+		"/.gitignore",
+		"/index.js",
+		"/keep.log",
+		"/lib/tmp/helper.js",
+		"/manifest.yml",
+	}
+
+	BeforeEach(func() {
+		var err error
+		tmp, err = os.MkdirTemp("", "gonut-ignore")
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(tmp)
+	})
+
+	It("should skip ignored files and version control directories of local directories", func() {
+		writeFiles(tmp, content)
+
+		directory, err := LoadSampleAppURL("file://"+tmp, "", "")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(fileNames(directory)).To(Equal(expected))
+	})
+
+	It("should skip version control directories without a .cfignore file", func() {
+		writeFiles(tmp, map[string]string{
+			".git/HEAD": "ref: refs/heads/main",
+			"index.js":  "console.log('Hello Gonut!')",
+			"debug.log": "debug output",
+		})
+
+		directory, err := LoadSampleAppURL("file://"+tmp, "", "")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(fileNames(directory)).To(Equal([]string{"/debug.log", "/index.js"}))
+	})
+
+	It("should skip ignored files of archives", func() {
+		var buf bytes.Buffer
+		w := zip.NewWriter(&buf)
+		for name, data := range content {
+			f, err := w.Create("app/" + name)
+			Expect(err).ToNot(HaveOccurred())
+			_, err = f.Write([]byte(data))
+			Expect(err).ToNot(HaveOccurred())
+		}
+
+		Expect(w.Close()).To(Succeed())
+
+		path := filepath.Join(tmp, "app.zip")
+		Expect(os.WriteFile(path, buf.Bytes(), 0644)).To(Succeed())
+
+		directory, err := LoadSampleAppArchive("file://"+path, "", "app")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(fileNames(directory)).To(Equal(expected))
+	})
+
+	It("should count the files and bytes of a directory", func() {
+		writeFiles(tmp, map[string]string{
+			"index.js":     "12345",
+			"lib/util.js":  "1234567890",
+			"public/a.txt": "",
+		})
+
+		directory, err := LoadSampleAppURL("file://"+tmp, "", "")
+		Expect(err).ToNot(HaveOccurred())
+
+		count, size := DirectoryStats(directory)
+		Expect(count).To(Equal(3))
+		Expect(size).To(Equal(int64(15)))
+	})
+})
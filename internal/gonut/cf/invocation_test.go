@@ -0,0 +1,73 @@
+// Copyright © 2019 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cf
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Cloud Foundry CLI call recording", func() {
+	var restore func()
+
+	BeforeEach(func() {
+		_, restore = installFakeCF(
+			fakeResponse{output: "one\n"},
+			fakeResponse{output: "two\n"},
+			fakeResponse{output: "three\n", exitCode: 1},
+			fakeResponse{output: "four\n"},
+		)
+	})
+
+	AfterEach(func() {
+		restore()
+	})
+
+	It("should only keep the calls made while a recording is active", func() {
+		_, _ = cf(nil, "target")
+
+		recorder := startRecording()
+		_, _ = cf(nil, "app", "gonut-app", "--guid")
+		_, _ = cf(nil, "logs", "gonut-app", "--recent")
+		invocations := recorder.stop()
+
+		_, _ = cf(nil, "delete", "gonut-app")
+
+		Expect(invocations).To(HaveLen(2))
+		Expect(invocations[0].Args).To(Equal([]string{"app", "gonut-app", "--guid"}))
+		Expect(invocations[1].Args).To(Equal([]string{"logs", "gonut-app", "--recent"}))
+		Expect(invocations[1].Err).To(HaveOccurred())
+		Expect(recorder.invocations).To(HaveLen(2))
+		Expect(recorders).To(BeEmpty())
+	})
+
+	It("should record the calls for each active recording", func() {
+		outer := startRecording()
+		_, _ = cf(nil, "target")
+
+		inner := startRecording()
+		_, _ = cf(nil, "app", "gonut-app", "--guid")
+
+		Expect(inner.stop()).To(HaveLen(1))
+		Expect(outer.stop()).To(HaveLen(2))
+		Expect(recorders).To(BeEmpty())
+	})
+})
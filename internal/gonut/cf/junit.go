@@ -0,0 +1,165 @@
+// Copyright © 2019 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cf
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/homeport/gonut/internal/gonut/nok"
+)
+
+// JUnitTestSuites is the root element of a JUnit XML report
+type JUnitTestSuites struct {
+	XMLName    xml.Name         `xml:"testsuites"`
+	TestSuites []JUnitTestSuite `xml:"testsuite"`
+}
+
+// JUnitTestSuite represents one gonut run in a JUnit XML report
+type JUnitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Errors    int             `xml:"errors,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	Time      string          `xml:"time,attr"`
+	Timestamp string          `xml:"timestamp,attr"`
+	TestCases []JUnitTestCase `xml:"testcase"`
+}
+
+// JUnitTestCase represents one sample app push in a JUnit XML report
+type JUnitTestCase struct {
+	Name       string          `xml:"name,attr"`
+	ClassName  string          `xml:"classname,attr"`
+	Time       string          `xml:"time,attr"`
+	Properties []JUnitProperty `xml:"properties>property,omitempty"`
+	Failure    *JUnitFailure   `xml:"failure,omitempty"`
+	Skipped    *JUnitSkipped   `xml:"skipped,omitempty"`
+}
+
+// JUnitProperty is a name/value pair with additional details of a test case
+type JUnitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+// JUnitFailure contains the details of a failed test case
+type JUnitFailure struct {
+	Message  string `xml:"message,attr"`
+	Type     string `xml:"type,attr"`
+	Contents string `xml:",chardata"`
+}
+
+// JUnitSkipped contains the reason why a test case was skipped
+type JUnitSkipped struct {
+	Message string `xml:"message,attr"`
+}
+
+// NewJUnitTestCase creates a JUnit test case for a sample app push, which is
+// marked as skipped in case a skip reason is provided, or as failed in case
+// of an error
+func NewJUnitTestCase(name string, report *PushReport, skipReason string, err error) JUnitTestCase {
+	testCase := JUnitTestCase{
+		Name:      name,
+		ClassName: "gonut.push",
+		Time:      junitSeconds(0),
+	}
+
+	if report != nil {
+		testCase.Time = junitSeconds(report.ElapsedTime())
+		for _, item := range report.Export() {
+			// The manifest is too verbose for a property
+			if item.Key == "manifest" {
+				continue
+			}
+
+			var value string
+			switch obj := item.Value.(type) {
+			case time.Duration:
+				value = junitSeconds(obj)
+
+			default:
+				value = fmt.Sprintf("%v", obj)
+			}
+
+			testCase.Properties = append(testCase.Properties, JUnitProperty{
+				Name:  fmt.Sprintf("%v", item.Key),
+				Value: value,
+			})
+		}
+	}
+
+	switch {
+	case err != nil:
+		testCase.Failure = &JUnitFailure{Type: "PushFailure", Message: err.Error()}
+
+		var details *nok.ErrorWithDetails
+		if errors.As(err, &details) {
+			testCase.Failure.Message = details.Caption
+			testCase.Failure.Contents = details.Details
+		}
+
+	case skipReason != "":
+		testCase.Skipped = &JUnitSkipped{Message: skipReason}
+	}
+
+	return testCase
+}
+
+// NewJUnitTestSuites creates a JUnit report with one test suite, which
+// contains the provided test cases
+func NewJUnitTestSuites(name string, timestamp time.Time, testCases []JUnitTestCase) JUnitTestSuites {
+	suite := JUnitTestSuite{
+		Name:      name,
+		Tests:     len(testCases),
+		Time:      junitSeconds(time.Since(timestamp)),
+		Timestamp: timestamp.UTC().Format("2006-01-02T15:04:05"),
+		TestCases: testCases,
+	}
+
+	for _, testCase := range testCases {
+		switch {
+		case testCase.Failure != nil:
+			suite.Failures++
+
+		case testCase.Skipped != nil:
+			suite.Skipped++
+		}
+	}
+
+	return JUnitTestSuites{TestSuites: []JUnitTestSuite{suite}}
+}
+
+// ToXML renders the JUnit report as XML document
+func (suites JUnitTestSuites) ToXML() ([]byte, error) {
+	data, err := xml.MarshalIndent(suites, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte(xml.Header), data...), nil
+}
+
+func junitSeconds(duration time.Duration) string {
+	return fmt.Sprintf("%.3f", duration.Seconds())
+}
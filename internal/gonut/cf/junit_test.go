@@ -0,0 +1,91 @@
+// Copyright © 2019 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cf_test
+
+import (
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/homeport/gonut/internal/gonut/cf"
+	"github.com/homeport/gonut/internal/gonut/nok"
+)
+
+var _ = Describe("JUnit XML report", func() {
+	Context("Creating test cases from push results", func() {
+		It("should list the phase timings as properties of a successful push", func() {
+			report := createMockReport("../../../assets/test/cf-push/api-2.133.0/push-and-delete.log")
+
+			testCase := NewJUnitTestCase("Golang on cflinuxfs3", report, "", nil)
+			Expect(testCase.Failure).To(BeNil())
+			Expect(testCase.Skipped).To(BeNil())
+			Expect(testCase.Properties).To(ContainElement(JUnitProperty{Name: "buildpack", Value: "(unknown)"}))
+		})
+
+		It("should not list the manifest as a property", func() {
+			report := createMockReport("../../../assets/test/cf-push/api-2.133.0/push-and-delete.log")
+			report.Manifest = "applications:\n- name: sample\n"
+
+			testCase := NewJUnitTestCase("Golang on cflinuxfs3", report, "", nil)
+			for _, property := range testCase.Properties {
+				Expect(property.Name).ToNot(Equal("manifest"))
+			}
+		})
+
+		It("should use the caption and details of a failed push", func() {
+			err := nok.Errorf("failed to push application", "staging failed")
+
+			testCase := NewJUnitTestCase("Golang on cflinuxfs3", nil, "", err)
+			Expect(testCase.Failure).ToNot(BeNil())
+			Expect(testCase.Failure.Message).To(Equal("failed to push application"))
+			Expect(testCase.Failure.Contents).To(Equal("staging failed"))
+		})
+
+		It("should use the error message for failures without details", func() {
+			testCase := NewJUnitTestCase("Golang on cflinuxfs3", nil, "", fmt.Errorf("no such app"))
+			Expect(testCase.Failure).ToNot(BeNil())
+			Expect(testCase.Failure.Message).To(Equal("no such app"))
+		})
+
+		It("should mark skipped pushes as skipped", func() {
+			testCase := NewJUnitTestCase("Swift on cflinuxfs3", nil, "there is no swift_buildpack installed", nil)
+			Expect(testCase.Skipped).ToNot(BeNil())
+			Expect(testCase.Skipped.Message).To(Equal("there is no swift_buildpack installed"))
+		})
+	})
+
+	Context("Rendering the report", func() {
+		It("should render one test suite with the test case counts", func() {
+			suites := NewJUnitTestSuites("gonut push", time.Now(), []JUnitTestCase{
+				NewJUnitTestCase("Golang", nil, "", nil),
+				NewJUnitTestCase("Python", nil, "", fmt.Errorf("failed")),
+				NewJUnitTestCase("Swift", nil, "skipped", nil),
+			})
+
+			data, err := suites.ToXML()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(data)).To(ContainSubstring(`<testsuite name="gonut push" tests="3" failures="1" errors="0" skipped="1"`))
+			Expect(string(data)).To(ContainSubstring(`<skipped message="skipped"></skipped>`))
+		})
+	})
+})
@@ -1,11 +1,19 @@
 package cf
 
 import (
+	"errors"
+	"fmt"
 	"net/url"
 	"os"
+	"regexp"
 
 	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/transport"
 	"github.com/homeport/pina-golada/pkg/files"
+
+	"github.com/homeport/gonut/internal/gonut/nok"
 )
 
 // HomeDir returns the HOME env key
@@ -16,43 +24,166 @@ func HomeDir() string {
 	return os.Getenv("USERPROFILE") // windows
 }
 
-func cloneOrPull(location string, url string) error {
-	// Gonut hasn't cloned the project before - execute clone
-	if _, err := os.Stat(location); os.IsNotExist(err) {
-		if _, err := git.PlainClone(location, false, &git.CloneOptions{URL: url}); err != nil {
-			return err
+// pinnedRef is the local reference that points to the fetched commit
+const pinnedRef = plumbing.ReferenceName("refs/gonut/pinned")
+
+var commitSHAPattern = regexp.MustCompile(`^[0-9a-f]{40}$`)
+
+// cloneOrPull makes sure that the local copy of the repository at the given
+// location contains the requested ref, which can be a branch, a tag, or a
+// full commit SHA, and checks it out. Without a ref, the default branch is
+// used. Only the requested commit is fetched, not the full history. The SHA
+// of the checked out commit is returned.
+func cloneOrPull(location string, url string, ref string) (commit string, err error) {
+	var repo *git.Repository
+
+	// Gonut hasn't cloned the project before - create an empty repository,
+	// which is removed again in case the initial fetch fails
+	if _, statErr := os.Stat(location); os.IsNotExist(statErr) {
+		defer func() {
+			if err != nil {
+				os.RemoveAll(location)
+			}
+		}()
+
+		if repo, err = git.PlainInit(location, false); err != nil {
+			return "", err
 		}
 
-		// A local copy of the project already exists - execute pull
-	} else {
-		r, err := git.PlainOpen(location)
-		if err != nil {
-			return err
+		if _, err = repo.CreateRemote(&config.RemoteConfig{Name: git.DefaultRemoteName, URLs: []string{url}}); err != nil {
+			return "", err
+		}
+
+		// A local copy of the project already exists
+	} else if repo, err = git.PlainOpen(location); err != nil {
+		return "", err
+	}
+
+	auth, err := gitAuth(url)
+	if err != nil {
+		return "", err
+	}
+
+	hash, err := fetchRef(repo, ref, auth)
+	if err != nil {
+		if isAuthError(err) {
+			return "", authError(url, err)
 		}
 
-		w, err := r.Worktree()
+		return "", err
+	}
+
+	w, err := repo.Worktree()
+	if err != nil {
+		return "", err
+	}
+
+	if err := w.Checkout(&git.CheckoutOptions{Hash: hash, Force: true}); err != nil {
+		return "", err
+	}
+
+	if err := touchCache(location); err != nil {
+		return "", err
+	}
+
+	return hash.String(), nil
+}
+
+// fetchRef fetches the commit of the given ref into the pinned reference and
+// returns its hash
+func fetchRef(repo *git.Repository, ref string, auth transport.AuthMethod) (plumbing.Hash, error) {
+	remote, err := repo.Remote(git.DefaultRemoteName)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+
+	source, err := resolveRemoteRef(remote, ref, auth)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+
+	err = repo.Fetch(&git.FetchOptions{
+		RemoteName: git.DefaultRemoteName,
+		RefSpecs:   []config.RefSpec{config.RefSpec(fmt.Sprintf("+%s:%s", source, pinnedRef))},
+		Depth:      1,
+		Tags:       git.NoTags,
+		Force:      true,
+		Auth:       auth,
+	})
+
+	if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return plumbing.ZeroHash, fmt.Errorf("failed to fetch %s from %s: %w", source, RedactURL(remote.Config().URLs[0]), err)
+	}
+
+	reference, err := repo.Reference(pinnedRef, true)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+
+	// Annotated tags point to a tag object instead of a commit
+	if tag, err := repo.TagObject(reference.Hash()); err == nil {
+		commit, err := tag.Commit()
 		if err != nil {
-			return err
+			return plumbing.ZeroHash, err
 		}
 
-		// Execute git pull in the specific worktree
-		err = w.Pull(&git.PullOptions{RemoteName: "origin"})
-		if err != nil && err.Error() != "already up-to-date" {
-			return err
+		return commit.Hash, nil
+	}
+
+	return reference.Hash(), nil
+}
+
+// resolveRemoteRef returns the name of the remote reference for the given
+// branch or tag name, or the ref itself in case it is a commit SHA
+func resolveRemoteRef(remote *git.Remote, ref string, auth transport.AuthMethod) (string, error) {
+	if ref == "" {
+		return string(plumbing.HEAD), nil
+	}
+
+	if commitSHAPattern.MatchString(ref) {
+		return ref, nil
+	}
+
+	references, err := remote.List(&git.ListOptions{Auth: auth})
+	if err != nil {
+		return "", err
+	}
+
+	for _, name := range []plumbing.ReferenceName{plumbing.NewBranchReferenceName(ref), plumbing.NewTagReferenceName(ref)} {
+		for _, reference := range references {
+			if reference.Name() == name {
+				return string(name), nil
+			}
 		}
 	}
 
-	return nil
+	return "", fmt.Errorf("there is no branch or tag %s in %s, commits need to be referenced using the full SHA", ref, RedactURL(remote.Config().URLs[0]))
 }
 
 // LoadSampleAppURL returns a in-memory directory from the provided path.
 // In case of a public url, it tries to clone/pull the git repository to the
 // .gonut/ directory and creates the in-memory directory from this copy.
+// Archives (zip, jar, tar.gz) are downloaded and extracted directly.
 func LoadSampleAppURL(rootURL string, relativePath string, localPath string) (files.Directory, error) {
+	directory, _, err := LoadSampleAppRef(rootURL, "", relativePath, localPath)
+	return directory, err
+}
+
+// LoadSampleAppRef works like LoadSampleAppURL, but checks out the given ref
+// of the git repository, which can be a branch, a tag or a commit SHA. The SHA
+// of the checked out commit is returned in addition to the directory, it is
+// empty for file URIs.
+func LoadSampleAppRef(rootURL string, ref string, relativePath string, localPath string) (files.Directory, string, error) {
 	// Split rootURL in its physical parts
 	u, err := url.Parse(rootURL)
 	if err != nil {
-		return nil, err
+		return nil, "", err
+	}
+
+	// In case of an archive, download and extract it directly
+	if archiveFormat(u.Path) != "" {
+		directory, err := LoadSampleAppArchive(rootURL, "", relativePath)
+		return directory, "", err
 	}
 
 	// Initialize in-memory directory
@@ -60,24 +191,34 @@ func LoadSampleAppURL(rootURL string, relativePath string, localPath string) (fi
 
 	// In case of a file URI, load directory directly and return
 	if u.Scheme == "file" {
-		err := files.LoadFromDisk(directory, u.Path)
+		err := loadFromDisk(directory, u.Path)
 		if err != nil {
-			return nil, err
+			return nil, "", err
 		}
 
-		return directory, nil
+		return directory, "", nil
 	}
 
 	// In case of an HTTP URL, try to clone/pull and load local path into directory
-	err = cloneOrPull(localPath, rootURL)
+	_, statErr := os.Stat(localPath)
+	commit, err := cloneOrPull(localPath, rootURL, ref)
 	if err != nil {
-		return nil, err
+		var details *nok.ErrorWithDetails
+		if statErr == nil && !errors.As(err, &details) {
+			return nil, "", nok.Errorf(
+				fmt.Sprintf("failed to update the local copy of %s in %s", RedactURL(rootURL), localPath),
+				"%v\n\nIn case the local copy is broken, use gonut cache refresh to clone it again",
+				err,
+			)
+		}
+
+		return nil, "", err
 	}
 
-	err = files.LoadFromDisk(directory, localPath+"/"+relativePath)
+	err = loadFromDisk(directory, localPath+"/"+relativePath)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
-	return directory, nil
+	return directory, commit, nil
 }
@@ -0,0 +1,133 @@
+// Copyright © 2019 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cf
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Sample App Location with git refs", func() {
+	var (
+		source    string
+		cache     string
+		firstSHA  string
+		secondSHA string
+	)
+
+	commit := func(repo *git.Repository, content string) string {
+		w, err := repo.Worktree()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(os.WriteFile(filepath.Join(source, "version.txt"), []byte(content), 0644)).To(Succeed())
+		_, err = w.Add("version.txt")
+		Expect(err).ToNot(HaveOccurred())
+
+		hash, err := w.Commit(content, &git.CommitOptions{Author: &object.Signature{Name: "gonut", Email: "gonut@example.org", When: time.Now()}})
+		Expect(err).ToNot(HaveOccurred())
+		return hash.String()
+	}
+
+	BeforeEach(func() {
+		var err error
+		source, err = os.MkdirTemp("", "gonut-source")
+		Expect(err).ToNot(HaveOccurred())
+
+		cache, err = os.MkdirTemp("", "gonut-cache")
+		Expect(err).ToNot(HaveOccurred())
+		cache = filepath.Join(cache, "repo")
+
+		repo, err := git.PlainInit(source, false)
+		Expect(err).ToNot(HaveOccurred())
+
+		cfg, err := repo.Config()
+		Expect(err).ToNot(HaveOccurred())
+		cfg.Raw.Section("uploadpack").SetOption("allowReachableSHA1InWant", "true")
+		Expect(repo.SetConfig(cfg)).To(Succeed())
+
+		firstSHA = commit(repo, "v1")
+		_, err = repo.CreateTag("v1.0.0", plumbing.NewHash(firstSHA), &git.CreateTagOptions{
+			Tagger:  &object.Signature{Name: "gonut", Email: "gonut@example.org", When: time.Now()},
+			Message: "v1.0.0",
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		secondSHA = commit(repo, "v2")
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(source)
+		os.RemoveAll(filepath.Dir(cache))
+	})
+
+	content := func() string {
+		data, err := os.ReadFile(filepath.Join(cache, "version.txt"))
+		Expect(err).ToNot(HaveOccurred())
+		return string(data)
+	}
+
+	It("should check out the default branch without a ref", func() {
+		sha, err := cloneOrPull(cache, "file://"+source, "")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(sha).To(Equal(secondSHA))
+		Expect(content()).To(Equal("v2"))
+	})
+
+	It("should check out tags, commits, and branches in the same local copy", func() {
+		sha, err := cloneOrPull(cache, "file://"+source, "v1.0.0")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(sha).To(Equal(firstSHA))
+		Expect(content()).To(Equal("v1"))
+
+		sha, err = cloneOrPull(cache, "file://"+source, "master")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(sha).To(Equal(secondSHA))
+		Expect(content()).To(Equal("v2"))
+
+		sha, err = cloneOrPull(cache, "file://"+source, firstSHA)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(sha).To(Equal(firstSHA))
+		Expect(content()).To(Equal("v1"))
+	})
+
+	It("should only fetch the requested commit", func() {
+		_, err := cloneOrPull(cache, "file://"+source, "master")
+		Expect(err).ToNot(HaveOccurred())
+
+		repo, err := git.PlainOpen(cache)
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = repo.CommitObject(plumbing.NewHash(firstSHA))
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should fail for refs that do not exist", func() {
+		_, err := cloneOrPull(cache, "file://"+source, "does-not-exist")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("does-not-exist"))
+	})
+})
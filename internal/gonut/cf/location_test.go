@@ -49,7 +49,7 @@ var _ = Describe("Sample App Location", func() {
 			})
 
 			JustBeforeEach(func() {
-				err = cloneOrPull(path, gonutMainTestingRepoURL)
+				_, err = cloneOrPull(path, gonutMainTestingRepoURL, "")
 			})
 
 			JustAfterEach(func() {
@@ -73,7 +73,7 @@ var _ = Describe("Sample App Location", func() {
 				Expect(err).ToNot(HaveOccurred())
 				Expect(os.IsNotExist(err)).To(BeFalse())
 
-				err = cloneOrPull(path, gonutMainTestingRepoURL)
+				_, err = cloneOrPull(path, gonutMainTestingRepoURL, "")
 				Expect(err).ToNot(HaveOccurred())
 
 				_, err = os.Stat(path)
@@ -94,7 +94,7 @@ var _ = Describe("Sample App Location", func() {
 			})
 
 			JustBeforeEach(func() {
-				err = cloneOrPull(path, gonutNoRemoteGitRepoURL)
+				_, err = cloneOrPull(path, gonutNoRemoteGitRepoURL, "")
 			})
 
 			JustAfterEach(func() {
@@ -128,7 +128,7 @@ var _ = Describe("Sample App Location", func() {
 				if setupErr != nil {
 					panic(setupErr)
 				}
-				err = cloneOrPull(path, gonutNoRemoteGitRepoURL)
+				_, err = cloneOrPull(path, gonutNoRemoteGitRepoURL, "")
 			})
 
 			JustAfterEach(func() {
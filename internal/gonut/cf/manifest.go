@@ -0,0 +1,372 @@
+// Copyright © 2019 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cf
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/homeport/pina-golada/pkg/files"
+	"github.com/homeport/pina-golada/pkg/files/paths"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// ManifestFile is the name of the sample app manifest
+const ManifestFile = "manifest.yml"
+
+// ManifestOverrides contains application settings that replace the ones of
+// the sample app manifest
+type ManifestOverrides struct {
+	Memory    string            `yaml:"memory"`
+	Disk      string            `yaml:"disk"`
+	Instances int               `yaml:"instances"`
+	Env       map[string]string `yaml:"env"`
+	VarsFiles []string          `yaml:"vars-files"`
+
+	// Buildpack, stack, and lifecycle are passed as flags when pushing, they
+	// are only rendered into the manifest of extracted sample apps
+	Buildpack string `yaml:"-"`
+	Stack     string `yaml:"-"`
+	Lifecycle string `yaml:"-"`
+}
+
+var variablePattern = regexp.MustCompile(`\(\(([-\w.]+)\)\)`)
+
+// IsEmpty returns whether there is nothing to override
+func (overrides ManifestOverrides) IsEmpty() bool {
+	return overrides.Memory == "" &&
+		overrides.Disk == "" &&
+		overrides.Instances == 0 &&
+		len(overrides.Env) == 0 &&
+		len(overrides.VarsFiles) == 0 &&
+		overrides.Buildpack == "" &&
+		overrides.Stack == "" &&
+		overrides.Lifecycle == ""
+}
+
+// Validate checks the overrides for obviously invalid settings
+func (overrides ManifestOverrides) Validate() error {
+	if overrides.Instances < 0 {
+		return fmt.Errorf("invalid number of instances %d", overrides.Instances)
+	}
+
+	for name := range overrides.Env {
+		if name == "" {
+			return fmt.Errorf("environment variable without a name")
+		}
+	}
+
+	return nil
+}
+
+// Merge returns the combination of both overrides, where the settings of the
+// other overrides take precedence. Environment variables are combined, and
+// vars files of the other overrides are used after the ones of these.
+func (overrides ManifestOverrides) Merge(other ManifestOverrides) ManifestOverrides {
+	result := overrides
+	if other.Memory != "" {
+		result.Memory = other.Memory
+	}
+
+	if other.Disk != "" {
+		result.Disk = other.Disk
+	}
+
+	if other.Instances != 0 {
+		result.Instances = other.Instances
+	}
+
+	if other.Buildpack != "" {
+		result.Buildpack = other.Buildpack
+	}
+
+	if other.Stack != "" {
+		result.Stack = other.Stack
+	}
+
+	if other.Lifecycle != "" {
+		result.Lifecycle = other.Lifecycle
+	}
+
+	if len(overrides.Env)+len(other.Env) > 0 {
+		result.Env = map[string]string{}
+		for _, env := range []map[string]string{overrides.Env, other.Env} {
+			for name, value := range env {
+				result.Env[name] = value
+			}
+		}
+	}
+
+	result.VarsFiles = append(append([]string{}, overrides.VarsFiles...), other.VarsFiles...)
+	return result
+}
+
+// ApplyManifest merges the overrides into the manifest of the sample app in
+// the directory, variables of the manifest are replaced with the values of
+// the vars files. In case the sample app has no manifest, a new one is
+// created. The effective manifest is returned.
+func ApplyManifest(directory files.Directory, appName string, overrides ManifestOverrides) (string, error) {
+	manifest := yaml.MapSlice{{Key: "applications", Value: []interface{}{yaml.MapSlice{{Key: "name", Value: appName}}}}}
+	if file := directory.File(paths.Of(ManifestFile)); file != nil {
+		var buf bytes.Buffer
+		if err := file.CopyContent(&buf); err != nil {
+			return "", err
+		}
+
+		manifest = yaml.MapSlice{}
+		if err := yaml.Unmarshal(buf.Bytes(), &manifest); err != nil {
+			return "", fmt.Errorf("failed to parse sample app manifest: %w", err)
+		}
+	}
+
+	vars, err := loadVarsFiles(overrides.VarsFiles)
+	if err != nil {
+		return "", err
+	}
+
+	var undefined []string
+	interpolated := interpolate(manifest, vars, &undefined)
+	if len(undefined) > 0 {
+		sort.Strings(undefined)
+		return "", fmt.Errorf("sample app manifest uses undefined variables: %s", strings.Join(undefined, ", "))
+	}
+
+	manifest = interpolated.(yaml.MapSlice)
+	applications, ok := lookup(manifest, "applications").([]interface{})
+	if !ok || len(applications) == 0 {
+		return "", fmt.Errorf("sample app manifest does not contain any applications")
+	}
+
+	for i, application := range applications {
+		settings, ok := application.(yaml.MapSlice)
+		if !ok {
+			return "", fmt.Errorf("sample app manifest contains an invalid application entry")
+		}
+
+		if overrides.Memory != "" {
+			settings = set(settings, "memory", overrides.Memory)
+		}
+
+		if overrides.Disk != "" {
+			settings = set(settings, "disk_quota", overrides.Disk)
+		}
+
+		if overrides.Instances > 0 {
+			settings = set(settings, "instances", overrides.Instances)
+		}
+
+		if len(overrides.Env) > 0 {
+			env, _ := lookup(settings, "env").(yaml.MapSlice)
+
+			names := make([]string, 0, len(overrides.Env))
+			for name := range overrides.Env {
+				names = append(names, name)
+			}
+
+			sort.Strings(names)
+			for _, name := range names {
+				env = set(env, name, overrides.Env[name])
+			}
+
+			settings = set(settings, "env", env)
+		}
+
+		if overrides.Buildpack != "" {
+			// The deprecated buildpack key cannot be used with buildpacks
+			settings = unset(settings, "buildpack")
+			settings = set(settings, "buildpacks", []string{overrides.Buildpack})
+		}
+
+		if overrides.Stack != "" {
+			settings = set(settings, "stack", overrides.Stack)
+		}
+
+		if overrides.Lifecycle != "" {
+			settings = set(settings, "lifecycle", overrides.Lifecycle)
+		}
+
+		applications[i] = settings
+	}
+
+	data, err := yaml.Marshal(manifest)
+	if err != nil {
+		return "", err
+	}
+
+	if err := directory.NewFile(paths.Of(ManifestFile)).Write(bytes.NewReader(data)); err != nil {
+		return "", err
+	}
+
+	return string(data), nil
+}
+
+// ReadManifest returns the manifest of the sample app in the directory as-is,
+// which is empty in case the sample app has no manifest
+func ReadManifest(directory files.Directory) (string, error) {
+	file := directory.File(paths.Of(ManifestFile))
+	if file == nil {
+		return "", nil
+	}
+
+	var buf bytes.Buffer
+	if err := file.CopyContent(&buf); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+// MaskManifest returns the manifest with the values of all environment
+// variables replaced, so that it can be shown without leaking secrets. In case
+// the manifest cannot be parsed, nothing is returned.
+func MaskManifest(manifest string) string {
+	var content yaml.MapSlice
+	if err := yaml.Unmarshal([]byte(manifest), &content); err != nil || len(content) == 0 {
+		return ""
+	}
+
+	applications, _ := lookup(content, "applications").([]interface{})
+	for i, application := range applications {
+		settings, ok := application.(yaml.MapSlice)
+		if !ok {
+			continue
+		}
+
+		env, ok := lookup(settings, "env").(yaml.MapSlice)
+		if !ok {
+			continue
+		}
+
+		masked := yaml.MapSlice{}
+		for _, item := range env {
+			masked = append(masked, yaml.MapItem{Key: item.Key, Value: "xxxxx"})
+		}
+
+		applications[i] = set(settings, "env", masked)
+	}
+
+	data, err := yaml.Marshal(content)
+	if err != nil {
+		return ""
+	}
+
+	return string(data)
+}
+
+// loadVarsFiles reads the variables of all vars files, where later files
+// take precedence
+func loadVarsFiles(paths []string) (map[string]interface{}, error) {
+	vars := map[string]interface{}{}
+	for _, path := range paths {
+		data, err := os.ReadFile(ExpandHomeDir(path))
+		if err != nil {
+			return nil, err
+		}
+
+		var content map[string]interface{}
+		if err := yaml.Unmarshal(data, &content); err != nil {
+			return nil, fmt.Errorf("failed to parse vars file %s: %w", path, err)
+		}
+
+		for name, value := range content {
+			vars[name] = value
+		}
+	}
+
+	return vars, nil
+}
+
+// interpolate replaces ((variable)) placeholders in all strings of the node,
+// a string that only consists of a placeholder is replaced with the value as
+// is, which keeps its type
+func interpolate(node interface{}, vars map[string]interface{}, undefined *[]string) interface{} {
+	switch obj := node.(type) {
+	case yaml.MapSlice:
+		for i := range obj {
+			obj[i].Value = interpolate(obj[i].Value, vars, undefined)
+		}
+
+		return obj
+
+	case []interface{}:
+		for i := range obj {
+			obj[i] = interpolate(obj[i], vars, undefined)
+		}
+
+		return obj
+
+	case string:
+		if matches := variablePattern.FindStringSubmatch(obj); matches != nil && matches[0] == obj {
+			if value, ok := vars[matches[1]]; ok {
+				return value
+			}
+		}
+
+		return variablePattern.ReplaceAllStringFunc(obj, func(placeholder string) string {
+			name := variablePattern.FindStringSubmatch(placeholder)[1]
+			if value, ok := vars[name]; ok {
+				return fmt.Sprint(value)
+			}
+
+			*undefined = append(*undefined, name)
+			return placeholder
+		})
+
+	default:
+		return node
+	}
+}
+
+func lookup(mapSlice yaml.MapSlice, key string) interface{} {
+	for _, item := range mapSlice {
+		if item.Key == key {
+			return item.Value
+		}
+	}
+
+	return nil
+}
+
+func set(mapSlice yaml.MapSlice, key string, value interface{}) yaml.MapSlice {
+	for i, item := range mapSlice {
+		if item.Key == key {
+			mapSlice[i].Value = value
+			return mapSlice
+		}
+	}
+
+	return append(mapSlice, yaml.MapItem{Key: key, Value: value})
+}
+
+func unset(mapSlice yaml.MapSlice, key string) yaml.MapSlice {
+	result := yaml.MapSlice{}
+	for _, item := range mapSlice {
+		if item.Key != key {
+			result = append(result, item)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,198 @@
+// Copyright © 2019 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cf_test
+
+import (
+	"bytes"
+	"os"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/homeport/pina-golada/pkg/files"
+	"github.com/homeport/pina-golada/pkg/files/paths"
+	yaml "gopkg.in/yaml.v2"
+
+	. "github.com/homeport/gonut/internal/gonut/cf"
+)
+
+var _ = Describe("Manifest overrides", func() {
+	sampleApp := func(manifest string) files.Directory {
+		directory := files.NewRootDirectory()
+		Expect(directory.NewFile(paths.Of("main.go")).Write(bytes.NewBufferString("package main"))).To(Succeed())
+
+		if manifest != "" {
+			data, err := os.ReadFile(manifest)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(directory.NewFile(paths.Of(ManifestFile)).Write(bytes.NewReader(data))).To(Succeed())
+		}
+
+		return directory
+	}
+
+	application := func(directory files.Directory) map[string]interface{} {
+		var buf bytes.Buffer
+		Expect(directory.File(paths.Of(ManifestFile)).CopyContent(&buf)).To(Succeed())
+
+		var manifest struct {
+			Applications []map[string]interface{} `yaml:"applications"`
+		}
+
+		Expect(yaml.Unmarshal(buf.Bytes(), &manifest)).To(Succeed())
+		Expect(manifest.Applications).To(HaveLen(1))
+		return manifest.Applications[0]
+	}
+
+	Context("merging overrides", func() {
+		It("should prefer the settings of the other overrides", func() {
+			base := ManifestOverrides{Memory: "256M", Disk: "1G", Env: map[string]string{"A": "1", "B": "2"}, VarsFiles: []string{"a.yml"}}
+			other := ManifestOverrides{Memory: "512M", Instances: 2, Env: map[string]string{"B": "3"}, VarsFiles: []string{"b.yml"}}
+
+			Expect(base.Merge(other)).To(Equal(ManifestOverrides{
+				Memory:    "512M",
+				Disk:      "1G",
+				Instances: 2,
+				Env:       map[string]string{"A": "1", "B": "3"},
+				VarsFiles: []string{"a.yml", "b.yml"},
+			}))
+
+			Expect(base.Env).To(Equal(map[string]string{"A": "1", "B": "2"}))
+		})
+
+		It("should know whether there is something to override", func() {
+			Expect(ManifestOverrides{}.IsEmpty()).To(BeTrue())
+			Expect(ManifestOverrides{}.Merge(ManifestOverrides{}).IsEmpty()).To(BeTrue())
+			Expect(ManifestOverrides{Instances: 1}.IsEmpty()).To(BeFalse())
+		})
+
+		It("should reject invalid settings", func() {
+			Expect(ManifestOverrides{Instances: -1}.Validate()).ToNot(Succeed())
+			Expect(ManifestOverrides{Env: map[string]string{"": "value"}}.Validate()).ToNot(Succeed())
+		})
+	})
+
+	Context("applying overrides to the sample app manifest", func() {
+		It("should merge the settings into the existing manifest", func() {
+			directory := sampleApp("../../../assets/test/manifest/manifest.yml")
+
+			manifest, err := ApplyManifest(directory, "gonut-golang-app-x", ManifestOverrides{
+				Disk:      "1G",
+				Env:       map[string]string{"GOVERSION": "go1.19", "GOPACKAGENAME": "other"},
+				VarsFiles: []string{"../../../assets/test/manifest/vars.yml"},
+			})
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(manifest).To(ContainSubstring("disk_quota: 1G"))
+
+			app := application(directory)
+			Expect(app).To(HaveKeyWithValue("name", "golang-sample-app"))
+			Expect(app).To(HaveKeyWithValue("memory", "64M"))
+			Expect(app).To(HaveKeyWithValue("instances", 3))
+			Expect(app).To(HaveKeyWithValue("disk_quota", "1G"))
+			Expect(app["env"]).To(Equal(map[interface{}]interface{}{
+				"GOPACKAGENAME": "other",
+				"GOVERSION":     "go1.19",
+				"MESSAGE":       "Hello Gonut!",
+			}))
+		})
+
+		It("should prefer the explicit settings and later vars files", func() {
+			directory := sampleApp("../../../assets/test/manifest/manifest.yml")
+
+			_, err := ApplyManifest(directory, "gonut-golang-app-x", ManifestOverrides{
+				Instances: 5,
+				VarsFiles: []string{"../../../assets/test/manifest/vars.yml", "../../../assets/test/manifest/override.yml"},
+			})
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(application(directory)).To(HaveKeyWithValue("memory", "96M"))
+			Expect(application(directory)).To(HaveKeyWithValue("instances", 5))
+		})
+
+		It("should create a manifest for sample apps without one", func() {
+			directory := sampleApp("")
+
+			_, err := ApplyManifest(directory, "gonut-golang-app-x", ManifestOverrides{Memory: "256M"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(application(directory)).To(Equal(map[string]interface{}{
+				"name":   "gonut-golang-app-x",
+				"memory": "256M",
+			}))
+		})
+
+		It("should render the buildpack, stack, and lifecycle", func() {
+			directory := files.NewRootDirectory()
+			Expect(directory.NewFile(paths.Of(ManifestFile)).Write(bytes.NewBufferString(`---
+applications:
+- name: golang-sample-app
+  buildpack: go_buildpack
+`))).To(Succeed())
+
+			_, err := ApplyManifest(directory, "gonut-golang-app-x", ManifestOverrides{
+				Buildpack: "docker://gcr.io/paketo-buildpacks/go",
+				Stack:     "cflinuxfs4",
+				Lifecycle: CNBLifecycle,
+			})
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(application(directory)).To(Equal(map[string]interface{}{
+				"name":       "golang-sample-app",
+				"buildpacks": []interface{}{"docker://gcr.io/paketo-buildpacks/go"},
+				"stack":      "cflinuxfs4",
+				"lifecycle":  "cnb",
+			}))
+		})
+
+		It("should fail for variables without a value", func() {
+			directory := sampleApp("../../../assets/test/manifest/manifest.yml")
+
+			_, err := ApplyManifest(directory, "gonut-golang-app-x", ManifestOverrides{Memory: "256M"})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("undefined variables: greeting, instances, memory"))
+		})
+
+		It("should fail for vars files that do not exist", func() {
+			directory := sampleApp("../../../assets/test/manifest/manifest.yml")
+
+			_, err := ApplyManifest(directory, "gonut-golang-app-x", ManifestOverrides{VarsFiles: []string{"does-not-exist.yml"}})
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("reading the sample app manifest", func() {
+		It("should return the manifest as-is", func() {
+			directory := sampleApp("../../../assets/test/manifest/manifest.yml")
+
+			expected, err := os.ReadFile("../../../assets/test/manifest/manifest.yml")
+			Expect(err).ToNot(HaveOccurred())
+
+			manifest, err := ReadManifest(directory)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(manifest).To(Equal(string(expected)))
+		})
+
+		It("should return nothing for sample apps without one", func() {
+			manifest, err := ReadManifest(sampleApp(""))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(manifest).To(BeEmpty())
+		})
+	})
+})
@@ -90,5 +90,27 @@ var _ = Describe("Cloud Foundry JSON structs and contracts", func() {
 			Expect(domain.Metadata.GUID).To(BeEquivalentTo("75049093-13e9-4520-80a6-2d6fea6542bc"))
 			Expect(domain.Entity.Name).To(BeEquivalentTo("eu-gb.mybluemix.net"))
 		})
+
+		It("should parse Cloud Foundry API app instances details", func() {
+			data, err := os.ReadFile("../../../assets/test/cf-curl/v2/apps/instances.json")
+			Expect(err).ToNot(HaveOccurred())
+
+			var instances map[string]InstanceDetails
+			Expect(json.Unmarshal(data, &instances)).ToNot(HaveOccurred())
+			Expect(instances).To(HaveLen(2))
+			Expect(instances["0"].State).To(BeEquivalentTo("RUNNING"))
+			Expect(instances["1"].State).To(BeEquivalentTo("STARTING"))
+		})
+
+		It("should parse Cloud Foundry API page of task details", func() {
+			data, err := os.ReadFile("../../../assets/test/cf-curl/v3/tasks/tasks-page.json")
+			Expect(err).ToNot(HaveOccurred())
+
+			var taskPage TaskPage
+			Expect(json.Unmarshal(data, &taskPage)).ToNot(HaveOccurred())
+			Expect(taskPage.Resources).To(HaveLen(1))
+			Expect(taskPage.Resources[0].State).To(BeEquivalentTo(TaskFailed))
+			Expect(taskPage.Resources[0].Result.FailureReason).To(ContainSubstring("Exited with status 127"))
+		})
 	})
 })
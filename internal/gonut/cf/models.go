@@ -37,6 +37,24 @@ const (
 	OnSuccess
 )
 
+// Supported staging lifecycles include:
+// - BuildpackLifecycle, the classic buildpack based staging
+// - CNBLifecycle, staging using Cloud Native Buildpacks
+const (
+	BuildpackLifecycle = "buildpack"
+	CNBLifecycle       = "cnb"
+)
+
+// Supported health check types include:
+// - PortHealthCheck, checks that the app accepts connections on its port
+// - ProcessHealthCheck, checks that the app process is running
+// - HTTPHealthCheck, checks that the app responds to a HTTP request
+const (
+	PortHealthCheck    = "port"
+	ProcessHealthCheck = "process"
+	HTTPHealthCheck    = "http"
+)
+
 // CloudFoundryConfig defines the structure used by the Cloud Foundry CLI configuration JSONs
 type CloudFoundryConfig struct {
 	ConfigVersion         int    `json:"ConfigVersion"`
@@ -240,3 +258,36 @@ type DomainDetails struct {
 		RouterGroupType interface{} `json:"router_group_type"`
 	} `json:"entity"`
 }
+
+// InstanceDetails is the Go struct for an entry of the /v2/apps/<guid>/instances result JSON
+type InstanceDetails struct {
+	State  string  `json:"state"`
+	Uptime int     `json:"uptime"`
+	Since  float64 `json:"since"`
+}
+
+// TaskDetails is the Go struct for the /v3/tasks/<guid> result JSON
+type TaskDetails struct {
+	GUID        string    `json:"guid"`
+	SequenceID  int       `json:"sequence_id"`
+	Name        string    `json:"name"`
+	Command     string    `json:"command"`
+	State       string    `json:"state"`
+	MemoryInMB  int       `json:"memory_in_mb"`
+	DiskInMB    int       `json:"disk_in_mb"`
+	DropletGUID string    `json:"droplet_guid"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	Result      struct {
+		FailureReason string `json:"failure_reason"`
+	} `json:"result"`
+}
+
+// TaskPage represents the result of cf curl /v3/apps/<guid>/tasks output
+type TaskPage struct {
+	Pagination struct {
+		TotalResults int `json:"total_results"`
+		TotalPages   int `json:"total_pages"`
+	} `json:"pagination"`
+	Resources []TaskDetails `json:"resources"`
+}
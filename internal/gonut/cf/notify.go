@@ -0,0 +1,320 @@
+// Copyright © 2019 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cf
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/homeport/gonut/internal/gonut/nok"
+)
+
+// Notification events
+const (
+	FailureEvent  = "failure"
+	RecoveryEvent = "recovery"
+)
+
+// Webhook formats
+const (
+	GenericWebhook = "generic"
+	SlackWebhook   = "slack"
+	TeamsWebhook   = "teams"
+)
+
+// Notification is the message sent to webhooks when a sample app push
+// failed, or when it works again after a previous failure
+type Notification struct {
+	Event     string            `json:"event"`
+	App       string            `json:"app"`
+	Target    string            `json:"target,omitempty"`
+	Org       string            `json:"org,omitempty"`
+	Space     string            `json:"space,omitempty"`
+	Stack     string            `json:"stack,omitempty"`
+	Buildpack string            `json:"buildpack,omitempty"`
+	Lifecycle string            `json:"lifecycle,omitempty"`
+	Caption   string            `json:"caption"`
+	Details   string            `json:"details,omitempty"`
+	Report    map[string]string `json:"report,omitempty"`
+	Timestamp time.Time         `json:"timestamp"`
+
+	facts []Fact
+}
+
+// Fact is one name/value pair of the push report export
+type Fact struct {
+	Name  string
+	Value string
+}
+
+// Webhook is an endpoint that receives notifications as JSON payload
+type Webhook struct {
+	URL      string
+	Format   string
+	Template *template.Template
+}
+
+var webhookTemplates = map[string]string{
+	SlackWebhook: `{
+  "text": {{ json .Title }},
+  "attachments": [
+    {
+      "color": "{{ if .Failed }}danger{{ else }}good{{ end }}",
+      "title": {{ json .Caption }},
+      "text": {{ json .Details }},
+      "fields": [{{ range $i, $fact := .Facts }}{{ if $i }},{{ end }}
+        {"title": {{ json $fact.Name }}, "value": {{ json $fact.Value }}, "short": true}{{ end }}
+      ],
+      "ts": {{ .Timestamp.Unix }}
+    }
+  ]
+}`,
+
+	TeamsWebhook: `{
+  "@type": "MessageCard",
+  "@context": "https://schema.org/extensions",
+  "themeColor": "{{ if .Failed }}D70000{{ else }}2DC72D{{ end }}",
+  "summary": {{ json .Title }},
+  "sections": [
+    {
+      "activityTitle": {{ json .Title }},
+      "activitySubtitle": {{ json .Caption }},
+      "text": {{ json .Details }},
+      "facts": [{{ range $i, $fact := .Facts }}{{ if $i }},{{ end }}
+        {"name": {{ json $fact.Name }}, "value": {{ json $fact.Value }}}{{ end }}
+      ]
+    }
+  ]
+}`,
+}
+
+// NewNotification creates a notification for the push described by the
+// history entry, the report can be nil if the push failed early
+func NewNotification(event string, entry HistoryEntry, report *PushReport, err error) Notification {
+	notification := Notification{
+		Event:     event,
+		App:       entry.App,
+		Target:    entry.Target,
+		Org:       entry.Org,
+		Space:     entry.Space,
+		Stack:     entry.Stack,
+		Buildpack: entry.Buildpack,
+		Lifecycle: entry.Lifecycle,
+		Timestamp: entry.Timestamp,
+	}
+
+	var details *nok.ErrorWithDetails
+	switch {
+	case errors.As(err, &details):
+		notification.Caption = details.Caption
+		notification.Details = details.Details
+
+	case err != nil:
+		notification.Caption = err.Error()
+
+	case event == RecoveryEvent:
+		notification.Caption = fmt.Sprintf("%s sample app push works again", entry.App)
+	}
+
+	if report != nil {
+		notification.Report = map[string]string{}
+		for _, item := range report.Export() {
+			// The manifest is too verbose for a chat message
+			if item.Key == "manifest" {
+				continue
+			}
+
+			fact := Fact{Name: fmt.Sprint(item.Key), Value: fmt.Sprint(item.Value)}
+			if duration, ok := item.Value.(time.Duration); ok {
+				fact.Value = HumanReadableDuration(duration)
+			}
+
+			notification.Report[fact.Name] = fact.Value
+			notification.facts = append(notification.facts, fact)
+		}
+	}
+
+	return notification
+}
+
+// Failed returns whether the notification is about a failed push
+func (notification Notification) Failed() bool {
+	return notification.Event == FailureEvent
+}
+
+// Title returns a short one line summary of the notification
+func (notification Notification) Title() string {
+	where := notification.Target
+	if notification.Space != "" {
+		where = fmt.Sprintf("%s/%s on %s", notification.Org, notification.Space, notification.Target)
+	}
+
+	verb := "failed"
+	if !notification.Failed() {
+		verb = "recovered"
+	}
+
+	if where == "" {
+		return fmt.Sprintf("gonut: %s sample app push %s", notification.App, verb)
+	}
+
+	return fmt.Sprintf("gonut: %s sample app push %s (%s)", notification.App, verb, where)
+}
+
+// Facts returns the push report details in the order of the report export
+func (notification Notification) Facts() []Fact {
+	return notification.facts
+}
+
+// ParseWebhook parses a webhook definition, which is the URL of the webhook
+// optionally prefixed with the payload format, for example slack=https://...
+func ParseWebhook(value string) (Webhook, error) {
+	webhook := Webhook{URL: value, Format: GenericWebhook}
+
+	if prefix, target, found := strings.Cut(value, "="); found {
+		switch prefix {
+		case GenericWebhook, SlackWebhook, TeamsWebhook:
+			webhook.URL, webhook.Format = target, prefix
+		}
+	}
+
+	if !strings.HasPrefix(webhook.URL, "http://") && !strings.HasPrefix(webhook.URL, "https://") {
+		return Webhook{}, nok.Errorf(
+			"invalid webhook",
+			"webhook %q needs to be a HTTP(S) URL, optionally prefixed with one of the formats %s, %s, or %s, for example %s=https://...",
+			value, GenericWebhook, SlackWebhook, TeamsWebhook, SlackWebhook,
+		)
+	}
+
+	if text, ok := webhookTemplates[webhook.Format]; ok {
+		webhook.Template = template.Must(newWebhookTemplate(webhook.Format).Parse(text))
+	}
+
+	return webhook, nil
+}
+
+// LoadWebhookTemplate reads a Go template file that renders the JSON payload
+// of a notification. The template has access to the notification fields and
+// a json function to quote values.
+func LoadWebhookTemplate(path string) (*template.Template, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return newWebhookTemplate(path).Parse(string(data))
+}
+
+func newWebhookTemplate(name string) *template.Template {
+	return template.New(name).Funcs(template.FuncMap{
+		"json": func(value interface{}) (string, error) {
+			data, err := json.Marshal(value)
+			return string(data), err
+		},
+	})
+}
+
+// Payload renders the notification in the format of the webhook
+func (webhook Webhook) Payload(notification Notification) ([]byte, error) {
+	if webhook.Template == nil {
+		return json.Marshal(notification)
+	}
+
+	var buf bytes.Buffer
+	if err := webhook.Template.Execute(&buf, notification); err != nil {
+		return nil, err
+	}
+
+	if !json.Valid(buf.Bytes()) {
+		return nil, fmt.Errorf("webhook template %s does not render valid JSON", webhook.Template.Name())
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Send posts the notification to the webhook
+func (webhook Webhook) Send(notification Notification) error {
+	payload, err := webhook.Payload(notification)
+	if err != nil {
+		return err
+	}
+
+	resp, err := http.Post(webhook.URL, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		// Drop the URL from the error, since it often contains a secret token
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			return urlErr.Err
+		}
+
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("webhook returned statuscode %d: %s", resp.StatusCode, body)
+	}
+
+	return nil
+}
+
+// SendNotifications sends all notifications to all webhooks and does not stop
+// in case one webhook fails
+func SendNotifications(webhooks []Webhook, notifications []Notification) error {
+	var failed []string
+	for _, webhook := range webhooks {
+		for _, notification := range notifications {
+			if err := webhook.Send(notification); err != nil {
+				failed = append(failed, fmt.Sprintf("%s (%s): %v", webhook.Host(), notification.App, err))
+			}
+		}
+	}
+
+	if len(failed) > 0 {
+		return nok.Errorf(
+			fmt.Sprintf("failed to send %d notifications", len(failed)),
+			strings.Join(failed, "\n"),
+		)
+	}
+
+	return nil
+}
+
+// Host returns the host of the webhook URL, since the rest of the URL often
+// contains a secret token that should not show up in error messages
+func (webhook Webhook) Host() string {
+	rest := webhook.URL[strings.Index(webhook.URL, "://")+3:]
+	if i := strings.IndexAny(rest, "/?#"); i >= 0 {
+		rest = rest[:i]
+	}
+
+	return rest
+}
@@ -0,0 +1,189 @@
+// Copyright © 2019 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cf_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/homeport/gonut/internal/gonut/cf"
+	"github.com/homeport/gonut/internal/gonut/nok"
+)
+
+var _ = Describe("Webhook notifications", func() {
+	var (
+		entry    HistoryEntry
+		report   *PushReport
+		payloads []map[string]interface{}
+		server   *httptest.Server
+	)
+
+	BeforeEach(func() {
+		entry = HistoryEntry{
+			Timestamp: time.Unix(1557837912, 0),
+			Target:    "https://api.cf.example.org",
+			Org:       "system",
+			Space:     "gonut",
+			App:       "Golang",
+			Stack:     "cflinuxfs3",
+			Buildpack: "go_buildpack",
+		}
+
+		report = createMockReport("../../../assets/test/cf-push/api-2.133.0/push-and-delete.log")
+
+		payloads = nil
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer GinkgoRecover()
+			Expect(r.Method).To(Equal(http.MethodPost))
+			Expect(r.Header.Get("Content-Type")).To(Equal("application/json"))
+
+			data, _ := io.ReadAll(r.Body)
+			var payload map[string]interface{}
+			Expect(json.Unmarshal(data, &payload)).To(Succeed())
+			payloads = append(payloads, payload)
+
+			w.WriteHeader(http.StatusOK)
+		}))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	Context("Webhook definitions", func() {
+		It("should use the generic format by default", func() {
+			webhook, err := ParseWebhook("https://hooks.example.org/gonut?token=secret")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(webhook.Format).To(Equal(GenericWebhook))
+			Expect(webhook.URL).To(Equal("https://hooks.example.org/gonut?token=secret"))
+			Expect(webhook.Host()).To(Equal("hooks.example.org"))
+		})
+
+		It("should support a format prefix", func() {
+			webhook, err := ParseWebhook("slack=https://hooks.slack.com/services/T0/B0/X")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(webhook.Format).To(Equal(SlackWebhook))
+			Expect(webhook.URL).To(Equal("https://hooks.slack.com/services/T0/B0/X"))
+		})
+
+		It("should fail for definitions that are no URLs", func() {
+			_, err := ParseWebhook("irc=#gonut")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("Sending notifications", func() {
+		It("should post the error details and the push report to generic webhooks", func() {
+			err := nok.Errorf("failed to push application", "staging failed")
+			notification := NewNotification(FailureEvent, entry, report, err)
+
+			webhook, _ := ParseWebhook(server.URL)
+			Expect(SendNotifications([]Webhook{webhook}, []Notification{notification})).To(Succeed())
+
+			Expect(payloads).To(HaveLen(1))
+			Expect(payloads[0]).To(HaveKeyWithValue("event", "failure"))
+			Expect(payloads[0]).To(HaveKeyWithValue("app", "Golang"))
+			Expect(payloads[0]).To(HaveKeyWithValue("caption", "failed to push application"))
+			Expect(payloads[0]).To(HaveKeyWithValue("details", "staging failed"))
+			Expect(payloads[0]).To(HaveKey("report"))
+			Expect(payloads[0]["report"]).To(HaveKey("staging"))
+		})
+
+		It("should leave the manifest out of the push report", func() {
+			withManifest := *report
+			withManifest.Manifest = "applications:\n- name: sample\n  env:\n    TOKEN: secret\n"
+
+			notification := NewNotification(FailureEvent, entry, &withManifest, nil)
+			Expect(notification.Report).ToNot(HaveKey("manifest"))
+		})
+
+		It("should render a Slack message for a recovery", func() {
+			notification := NewNotification(RecoveryEvent, entry, report, nil)
+
+			webhook, _ := ParseWebhook("slack=" + server.URL)
+			Expect(SendNotifications([]Webhook{webhook}, []Notification{notification})).To(Succeed())
+
+			Expect(payloads).To(HaveLen(1))
+			Expect(payloads[0]["text"]).To(Equal("gonut: Golang sample app push recovered (system/gonut on https://api.cf.example.org)"))
+
+			attachment := payloads[0]["attachments"].([]interface{})[0].(map[string]interface{})
+			Expect(attachment).To(HaveKeyWithValue("color", "good"))
+			Expect(attachment).To(HaveKeyWithValue("title", "Golang sample app push works again"))
+			Expect(attachment["fields"]).ToNot(BeEmpty())
+		})
+
+		It("should render a Teams message card for a failure", func() {
+			notification := NewNotification(FailureEvent, entry, nil, nok.Errorf("app did not start", "\"quoted\" details\nwith a second line"))
+
+			webhook, _ := ParseWebhook("teams=" + server.URL)
+			Expect(SendNotifications([]Webhook{webhook}, []Notification{notification})).To(Succeed())
+
+			Expect(payloads).To(HaveLen(1))
+			Expect(payloads[0]).To(HaveKeyWithValue("@type", "MessageCard"))
+			Expect(payloads[0]).To(HaveKeyWithValue("themeColor", "D70000"))
+
+			section := payloads[0]["sections"].([]interface{})[0].(map[string]interface{})
+			Expect(section).To(HaveKeyWithValue("activitySubtitle", "app did not start"))
+			Expect(section).To(HaveKeyWithValue("text", "\"quoted\" details\nwith a second line"))
+		})
+
+		It("should use a custom payload template", func() {
+			dir, err := os.MkdirTemp("", "gonut-test")
+			Expect(err).ToNot(HaveOccurred())
+			defer os.RemoveAll(dir)
+
+			path := filepath.Join(dir, "payload.tmpl")
+			Expect(os.WriteFile(path, []byte(`{"summary": {{ json .Title }}, "failed": {{ .Failed }}}`), 0644)).To(Succeed())
+
+			webhook, _ := ParseWebhook(server.URL)
+			webhook.Template, err = LoadWebhookTemplate(path)
+			Expect(err).ToNot(HaveOccurred())
+
+			notification := NewNotification(FailureEvent, entry, nil, nok.Errorf("failed", "details"))
+			Expect(SendNotifications([]Webhook{webhook}, []Notification{notification})).To(Succeed())
+
+			Expect(payloads).To(HaveLen(1))
+			Expect(payloads[0]).To(HaveKeyWithValue("summary", "gonut: Golang sample app push failed (system/gonut on https://api.cf.example.org)"))
+			Expect(payloads[0]).To(HaveKeyWithValue("failed", true))
+		})
+
+		It("should report webhooks that reject the notification without revealing the URL", func() {
+			failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusForbidden)
+			}))
+			defer failing.Close()
+
+			webhook, _ := ParseWebhook(failing.URL + "/secret-token")
+			err := SendNotifications([]Webhook{webhook}, []Notification{NewNotification(FailureEvent, entry, nil, nil)})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("statuscode 403"))
+			Expect(err.Error()).ToNot(ContainSubstring("secret-token"))
+		})
+	})
+})
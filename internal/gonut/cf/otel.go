@@ -0,0 +1,238 @@
+// Copyright © 2019 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cf
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Span kinds and status codes as defined by the OpenTelemetry protocol
+const (
+	otlpSpanKindInternal = 1
+	otlpSpanKindClient   = 3
+
+	otlpStatusOk    = 1
+	otlpStatusError = 2
+)
+
+// TracedPush contains the details of one sample app push to be exported as
+// part of a trace
+type TracedPush struct {
+	Name       string
+	Report     *PushReport
+	Err        error
+	Attributes map[string]string
+}
+
+// OTLPTraces is the OTLP/JSON representation of a trace export request
+type OTLPTraces struct {
+	ResourceSpans []OTLPResourceSpans `json:"resourceSpans"`
+}
+
+// OTLPResourceSpans groups spans by the resource that produced them
+type OTLPResourceSpans struct {
+	Resource struct {
+		Attributes []OTLPAttribute `json:"attributes"`
+	} `json:"resource"`
+	ScopeSpans []OTLPScopeSpans `json:"scopeSpans"`
+}
+
+// OTLPScopeSpans groups spans by the instrumentation scope
+type OTLPScopeSpans struct {
+	Scope struct {
+		Name    string `json:"name"`
+		Version string `json:"version,omitempty"`
+	} `json:"scope"`
+	Spans []OTLPSpan `json:"spans"`
+}
+
+// OTLPSpan is a single span in OTLP/JSON representation
+type OTLPSpan struct {
+	TraceID           string          `json:"traceId"`
+	SpanID            string          `json:"spanId"`
+	ParentSpanID      string          `json:"parentSpanId,omitempty"`
+	Name              string          `json:"name"`
+	Kind              int             `json:"kind"`
+	StartTimeUnixNano string          `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string          `json:"endTimeUnixNano"`
+	Attributes        []OTLPAttribute `json:"attributes,omitempty"`
+	Status            OTLPStatus      `json:"status"`
+}
+
+// OTLPAttribute is a key/value pair with a string value
+type OTLPAttribute struct {
+	Key   string `json:"key"`
+	Value struct {
+		StringValue string `json:"stringValue"`
+	} `json:"value"`
+}
+
+// OTLPStatus is the status of a span
+type OTLPStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+// NewPushTrace creates one trace for a gonut run. The run span is the root of
+// the trace, every sample app push is a child of it and has child spans for
+// each push phase and each Cloud Foundry CLI call.
+func NewPushTrace(name string, version string, start time.Time, end time.Time, pushes []TracedPush) OTLPTraces {
+	traceID := randomHexID(16)
+	runSpan := newSpan(traceID, "", name, otlpSpanKindInternal, start, end, nil, nil)
+	spans := []OTLPSpan{runSpan}
+
+	for _, push := range pushes {
+		if push.Report == nil {
+			continue
+		}
+
+		pushStart, pushEnd := push.Report.timeframe()
+		pushSpan := newSpan(traceID, runSpan.SpanID, push.Name, otlpSpanKindInternal, pushStart, pushEnd, push.Attributes, push.Err)
+		spans = append(spans, pushSpan)
+
+		for _, phase := range Phases {
+			phaseStart, duration := phase.Start(*push.Report), phase.Duration(*push.Report)
+			if phaseStart.IsZero() || duration <= 0 {
+				continue
+			}
+
+			spans = append(spans, newSpan(traceID, pushSpan.SpanID, phase.Name, otlpSpanKindInternal, phaseStart, phaseStart.Add(duration), nil, nil))
+		}
+
+		for _, invocation := range push.Report.Invocations {
+			attributes := map[string]string{"cf.command": strings.Join(invocation.Args, " ")}
+			spans = append(spans, newSpan(traceID, pushSpan.SpanID, "cf "+invocation.Args[0], otlpSpanKindClient, invocation.Start, invocation.End, attributes, invocation.Err))
+		}
+	}
+
+	scopeSpans := OTLPScopeSpans{Spans: spans}
+	scopeSpans.Scope.Name = "gonut"
+	scopeSpans.Scope.Version = version
+
+	resourceSpans := OTLPResourceSpans{ScopeSpans: []OTLPScopeSpans{scopeSpans}}
+	resourceSpans.Resource.Attributes = otlpAttributes(map[string]string{
+		"service.name":    "gonut",
+		"service.version": version,
+	})
+
+	return OTLPTraces{ResourceSpans: []OTLPResourceSpans{resourceSpans}}
+}
+
+// ToJSON renders the traces in the OTLP/JSON format
+func (traces OTLPTraces) ToJSON() ([]byte, error) {
+	return json.MarshalIndent(traces, "", "  ")
+}
+
+// ExportTraces sends the traces to an OTLP/HTTP endpoint
+func ExportTraces(endpoint string, traces OTLPTraces) error {
+	data, err := json.Marshal(traces)
+	if err != nil {
+		return err
+	}
+
+	target := endpoint
+	if !strings.HasSuffix(target, "/v1/traces") {
+		target = strings.TrimRight(target, "/") + "/v1/traces"
+	}
+
+	resp, err := http.Post(target, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("OTLP endpoint %s returned statuscode %d: %s", target, resp.StatusCode, body)
+	}
+
+	return nil
+}
+
+// timeframe returns the earliest and latest timestamp known for the push
+func (report PushReport) timeframe() (time.Time, time.Time) {
+	start, end := report.InitStart, report.PushEnd
+	for _, invocation := range report.Invocations {
+		if start.IsZero() || invocation.Start.Before(start) {
+			start = invocation.Start
+		}
+
+		if invocation.End.After(end) {
+			end = invocation.End
+		}
+	}
+
+	return start, end
+}
+
+func newSpan(traceID string, parentSpanID string, name string, kind int, start time.Time, end time.Time, attributes map[string]string, err error) OTLPSpan {
+	span := OTLPSpan{
+		TraceID:           traceID,
+		SpanID:            randomHexID(8),
+		ParentSpanID:      parentSpanID,
+		Name:              name,
+		Kind:              kind,
+		StartTimeUnixNano: strconv.FormatInt(start.UnixNano(), 10),
+		EndTimeUnixNano:   strconv.FormatInt(end.UnixNano(), 10),
+		Attributes:        otlpAttributes(attributes),
+		Status:            OTLPStatus{Code: otlpStatusOk},
+	}
+
+	if err != nil {
+		span.Status = OTLPStatus{Code: otlpStatusError, Message: err.Error()}
+	}
+
+	return span
+}
+
+func otlpAttributes(attributes map[string]string) []OTLPAttribute {
+	keys := make([]string, 0, len(attributes))
+	for key := range attributes {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	var result []OTLPAttribute
+	for _, key := range keys {
+		attribute := OTLPAttribute{Key: key}
+		attribute.Value.StringValue = attributes[key]
+		result = append(result, attribute)
+	}
+
+	return result
+}
+
+func randomHexID(length int) string {
+	id := make([]byte, length)
+	_, _ = rand.Read(id)
+	return hex.EncodeToString(id)
+}
@@ -0,0 +1,111 @@
+// Copyright © 2019 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cf_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/homeport/gonut/internal/gonut/cf"
+)
+
+var _ = Describe("OpenTelemetry trace export", func() {
+	var pushes []TracedPush
+
+	BeforeEach(func() {
+		report := createMockReport("../../../assets/test/cf-push/api-2.133.0/push-and-delete.log")
+		report.Invocations = []Invocation{
+			{Args: []string{"push", "the-app-name"}, Start: report.InitStart, End: report.PushEnd},
+			{Args: []string{"delete", "the-app-name", "-r", "-f"}, Start: report.PushEnd, End: report.PushEnd.Add(time.Second), Err: fmt.Errorf("exit status 1")},
+		}
+
+		pushes = []TracedPush{
+			{Name: "Golang on cflinuxfs3", Report: report, Attributes: map[string]string{"gonut.app": "Golang"}},
+			{Name: "Swift on cflinuxfs3"},
+		}
+	})
+
+	Context("Creating a trace of a run", func() {
+		It("should create a run span with push, phase and cf invocation child spans", func() {
+			start := time.Now()
+			traces := NewPushTrace("gonut push", "development", start, start.Add(time.Minute), pushes)
+
+			Expect(traces.ResourceSpans).To(HaveLen(1))
+			Expect(traces.ResourceSpans[0].ScopeSpans).To(HaveLen(1))
+
+			spans := traces.ResourceSpans[0].ScopeSpans[0].Spans
+			Expect(len(spans)).To(BeNumerically(">=", 4))
+
+			runSpan, pushSpan := spans[0], spans[1]
+			Expect(runSpan.ParentSpanID).To(BeEmpty())
+			Expect(runSpan.TraceID).To(HaveLen(32))
+			Expect(runSpan.SpanID).To(HaveLen(16))
+			Expect(pushSpan.Name).To(Equal("Golang on cflinuxfs3"))
+			Expect(pushSpan.ParentSpanID).To(Equal(runSpan.SpanID))
+
+			for _, span := range spans[2:] {
+				Expect(span.TraceID).To(Equal(runSpan.TraceID))
+				Expect(span.ParentSpanID).To(Equal(pushSpan.SpanID))
+			}
+
+			last := spans[len(spans)-1]
+			Expect(last.Name).To(Equal("cf delete"))
+			Expect(last.Status.Code).To(Equal(2))
+			Expect(last.Status.Message).To(Equal("exit status 1"))
+		})
+	})
+
+	Context("Exporting a trace", func() {
+		It("should post the trace in OTLP/JSON format to the traces endpoint", func() {
+			var path, contentType string
+			var received OTLPTraces
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				path, contentType = r.URL.Path, r.Header.Get("Content-Type")
+				data, _ := io.ReadAll(r.Body)
+				_ = json.Unmarshal(data, &received)
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			traces := NewPushTrace("gonut push", "development", time.Now(), time.Now(), pushes)
+			Expect(ExportTraces(server.URL, traces)).To(Succeed())
+			Expect(path).To(Equal("/v1/traces"))
+			Expect(contentType).To(Equal("application/json"))
+			Expect(received.ResourceSpans[0].ScopeSpans[0].Spans).To(HaveLen(len(traces.ResourceSpans[0].ScopeSpans[0].Spans)))
+		})
+
+		It("should fail if the endpoint rejects the trace", func() {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusInternalServerError)
+			}))
+			defer server.Close()
+
+			Expect(ExportTraces(server.URL, NewPushTrace("gonut push", "development", time.Now(), time.Now(), pushes))).ToNot(Succeed())
+		})
+	})
+})
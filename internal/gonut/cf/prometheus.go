@@ -0,0 +1,255 @@
+// Copyright © 2019 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cf
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// PushMetrics contains the outcome of one sample app push to be exported as
+// Prometheus metrics
+type PushMetrics struct {
+	App         string
+	Stack       string
+	Buildpack   string
+	Lifecycle   string
+	HealthCheck string
+	Report      *PushReport
+	Success     bool
+	Timestamp   time.Time
+}
+
+// labels returns the Prometheus labels of the push, which only consist of the
+// requested settings, so that failed and successful pushes of the same sample
+// app end up in the same series
+func (metrics PushMetrics) labels() string {
+	lifecycle := metrics.Lifecycle
+	if lifecycle == "" {
+		lifecycle = BuildpackLifecycle
+	}
+
+	return fmt.Sprintf(`app="%s",stack="%s",buildpack="%s",lifecycle="%s",health_check="%s"`,
+		escapeLabelValue(metrics.App),
+		escapeLabelValue(metrics.Stack),
+		escapeLabelValue(metrics.Buildpack),
+		escapeLabelValue(lifecycle),
+		escapeLabelValue(metrics.HealthCheck),
+	)
+}
+
+type metricFamily struct {
+	name    string
+	help    string
+	samples func(metrics PushMetrics, labels string) []string
+}
+
+var metricFamilies = []metricFamily{
+	{
+		name: "gonut_push_success",
+		help: "Whether the sample app push succeeded (1) or failed (0).",
+		samples: func(metrics PushMetrics, labels string) []string {
+			value := 0
+			if metrics.Success {
+				value = 1
+			}
+
+			return []string{fmt.Sprintf("gonut_push_success{%s} %d", labels, value)}
+		},
+	},
+
+	{
+		name: "gonut_push_duration_seconds",
+		help: "Overall duration of the sample app push in seconds.",
+		samples: func(metrics PushMetrics, labels string) []string {
+			if metrics.Report == nil || metrics.Report.PushEnd.IsZero() {
+				return nil
+			}
+
+			return []string{fmt.Sprintf("gonut_push_duration_seconds{%s} %g", labels, metrics.Report.ElapsedTime().Seconds())}
+		},
+	},
+
+	{
+		name: "gonut_push_phase_duration_seconds",
+		help: "Duration of the individual sample app push phases in seconds.",
+		samples: func(metrics PushMetrics, labels string) []string {
+			if metrics.Report == nil || !metrics.Report.HasTimeDetails() {
+				return nil
+			}
+
+			var result []string
+			for _, phase := range Phases {
+				result = append(result, fmt.Sprintf(`gonut_push_phase_duration_seconds{%s,phase="%s"} %g`,
+					labels,
+					phase.Name,
+					phase.Duration(*metrics.Report).Seconds(),
+				))
+			}
+
+			return result
+		},
+	},
+
+	{
+		name: "gonut_push_status_code",
+		help: "HTTP status code returned by the pushed sample app.",
+		samples: func(metrics PushMetrics, labels string) []string {
+			if metrics.Report == nil || metrics.Report.StatusCode == 0 {
+				return nil
+			}
+
+			return []string{fmt.Sprintf("gonut_push_status_code{%s} %d", labels, metrics.Report.StatusCode)}
+		},
+	},
+
+	{
+		name: "gonut_push_timestamp_seconds",
+		help: "Unix timestamp of the sample app push.",
+		samples: func(metrics PushMetrics, labels string) []string {
+			return []string{fmt.Sprintf("gonut_push_timestamp_seconds{%s} %d", labels, metrics.Timestamp.Unix())}
+		},
+	},
+}
+
+// LatestPushMetrics reduces the push metrics to the most recent one of each
+// combination of app, stack, buildpack, lifecycle, and health check, since a scrape must
+// not contain the same series more than once
+func LatestPushMetrics(pushMetrics []PushMetrics) []PushMetrics {
+	var (
+		result []PushMetrics
+		index  = map[string]int{}
+	)
+
+	for _, metrics := range pushMetrics {
+		labels := metrics.labels()
+		if i, ok := index[labels]; ok {
+			if !metrics.Timestamp.Before(result[i].Timestamp) {
+				result[i] = metrics
+			}
+
+			continue
+		}
+
+		index[labels] = len(result)
+		result = append(result, metrics)
+	}
+
+	return result
+}
+
+// WritePrometheusMetrics writes the push metrics in the Prometheus text
+// exposition format
+func WritePrometheusMetrics(w io.Writer, pushMetrics []PushMetrics) error {
+	for _, family := range metricFamilies {
+		var samples []string
+		for _, metrics := range pushMetrics {
+			samples = append(samples, family.samples(metrics, metrics.labels())...)
+		}
+
+		if len(samples) == 0 {
+			continue
+		}
+
+		if _, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s gauge\n%s\n",
+			family.name,
+			family.help,
+			family.name,
+			strings.Join(samples, "\n"),
+		); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// WritePrometheusFile writes the push metrics to the given file, which can be
+// picked up by the textfile collector of the Prometheus node exporter. The
+// file is replaced atomically so that the collector never reads partial data.
+func WritePrometheusFile(path string, pushMetrics []PushMetrics) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".gonut-metrics-")
+	if err != nil {
+		return err
+	}
+
+	defer os.Remove(tmp.Name())
+
+	if err := WritePrometheusMetrics(tmp, pushMetrics); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// PushToGateway sends the push metrics to a Prometheus Pushgateway, replacing
+// all metrics previously pushed for the given job
+func PushToGateway(gatewayURL string, job string, pushMetrics []PushMetrics) error {
+	var buf bytes.Buffer
+	if err := WritePrometheusMetrics(&buf, pushMetrics); err != nil {
+		return err
+	}
+
+	target := fmt.Sprintf("%s/metrics/job/%s",
+		strings.TrimRight(gatewayURL, "/"),
+		url.PathEscape(job),
+	)
+
+	req, err := http.NewRequest(http.MethodPut, target, &buf)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "text/plain; version=0.0.4")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("pushgateway %s returned statuscode %d: %s", gatewayURL, resp.StatusCode, body)
+	}
+
+	return nil
+}
+
+func escapeLabelValue(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
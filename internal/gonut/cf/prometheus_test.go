@@ -0,0 +1,150 @@
+// Copyright © 2019 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cf_test
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/homeport/gonut/internal/gonut/cf"
+)
+
+var _ = Describe("Prometheus metrics export", func() {
+	var pushMetrics []PushMetrics
+
+	BeforeEach(func() {
+		report := createMockReport("../../../assets/test/cf-push/api-2.133.0/push-and-delete.log")
+		report.StatusCode = 200
+
+		pushMetrics = []PushMetrics{
+			{App: "Golang", Stack: "cflinuxfs3", Buildpack: "go_buildpack", HealthCheck: "http", Report: report, Success: true, Timestamp: time.Unix(1557837912, 0)},
+			{App: "Python", Stack: "cflinuxfs3", Buildpack: "python_buildpack", Success: false, Timestamp: time.Unix(1557837912, 0)},
+		}
+	})
+
+	Context("Text exposition format", func() {
+		It("should write one sample per push with the push details as labels", func() {
+			var buf bytes.Buffer
+			Expect(WritePrometheusMetrics(&buf, pushMetrics)).To(Succeed())
+
+			out := buf.String()
+			Expect(out).To(ContainSubstring("# TYPE gonut_push_success gauge\n"))
+			Expect(out).To(ContainSubstring(`gonut_push_success{app="Golang",stack="cflinuxfs3",buildpack="go_buildpack",lifecycle="buildpack",health_check="http"} 1`))
+			Expect(out).To(ContainSubstring(`gonut_push_success{app="Python",stack="cflinuxfs3",buildpack="python_buildpack",lifecycle="buildpack",health_check=""} 0`))
+			Expect(out).To(ContainSubstring(`gonut_push_status_code{app="Golang",stack="cflinuxfs3",buildpack="go_buildpack",lifecycle="buildpack",health_check="http"} 200`))
+			Expect(out).To(ContainSubstring(`gonut_push_timestamp_seconds{app="Python",stack="cflinuxfs3",buildpack="python_buildpack",lifecycle="buildpack",health_check=""} 1557837912`))
+		})
+
+		It("should escape special characters in label values", func() {
+			var buf bytes.Buffer
+			Expect(WritePrometheusMetrics(&buf, []PushMetrics{{App: `a "quoted" \ app`}})).To(Succeed())
+			Expect(buf.String()).To(ContainSubstring(`app="a \"quoted\" \\ app"`))
+		})
+
+		It("should write the metrics to a file", func() {
+			dir, err := os.MkdirTemp("", "gonut-test")
+			Expect(err).ToNot(HaveOccurred())
+			defer os.RemoveAll(dir)
+
+			path := filepath.Join(dir, "gonut.prom")
+			Expect(WritePrometheusFile(path, pushMetrics)).To(Succeed())
+
+			data, err := os.ReadFile(path)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(data)).To(ContainSubstring("gonut_push_success"))
+		})
+	})
+
+	Context("Repeated pushes", func() {
+		It("should only keep the most recent metrics of the same push", func() {
+			newer := PushMetrics{App: "Python", Stack: "cflinuxfs3", Buildpack: "python_buildpack", Success: true, Timestamp: time.Unix(1557838912, 0)}
+
+			latest := LatestPushMetrics(append(pushMetrics, newer))
+			Expect(latest).To(HaveLen(2))
+			Expect(latest[0].App).To(Equal("Golang"))
+			Expect(latest[1]).To(Equal(newer))
+		})
+
+		It("should replace a failed push with the successful push of the same settings", func() {
+			report := createMockReport("../../../assets/test/cf-push/api-2.133.0/push-and-delete.log")
+			failed := PushMetrics{App: "Golang", Success: false, Timestamp: time.Unix(1557837912, 0)}
+			succeeded := PushMetrics{App: "Golang", Report: report, Success: true, Timestamp: time.Unix(1557838912, 0)}
+
+			latest := LatestPushMetrics([]PushMetrics{failed, succeeded})
+			Expect(latest).To(Equal([]PushMetrics{succeeded}))
+
+			var buf bytes.Buffer
+			Expect(WritePrometheusMetrics(&buf, latest)).To(Succeed())
+			Expect(buf.String()).To(ContainSubstring(`gonut_push_success{app="Golang",stack="",buildpack="",lifecycle="buildpack",health_check=""} 1`))
+			Expect(buf.String()).ToNot(ContainSubstring("} 0\n"))
+		})
+
+		It("should keep the metrics of pushes with different health checks", func() {
+			var metrics []PushMetrics
+			for _, healthCheck := range []string{"port", "process", "http"} {
+				metrics = append(metrics, PushMetrics{App: "Golang", Stack: "cflinuxfs3", Buildpack: "go_buildpack", HealthCheck: healthCheck, Success: true, Timestamp: time.Unix(1557837912, 0)})
+			}
+
+			latest := LatestPushMetrics(metrics)
+			Expect(latest).To(HaveLen(3))
+
+			var buf bytes.Buffer
+			Expect(WritePrometheusMetrics(&buf, latest)).To(Succeed())
+			Expect(buf.String()).To(ContainSubstring(`health_check="port"} 1`))
+			Expect(buf.String()).To(ContainSubstring(`health_check="process"} 1`))
+			Expect(buf.String()).To(ContainSubstring(`health_check="http"} 1`))
+		})
+	})
+
+	Context("Pushgateway", func() {
+		It("should replace the metrics of the gonut job", func() {
+			var method, path, body string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				data, _ := io.ReadAll(r.Body)
+				method, path, body = r.Method, r.URL.Path, string(data)
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			Expect(PushToGateway(server.URL, "gonut", pushMetrics)).To(Succeed())
+			Expect(method).To(Equal(http.MethodPut))
+			Expect(path).To(Equal("/metrics/job/gonut"))
+			Expect(body).To(ContainSubstring("gonut_push_success"))
+		})
+
+		It("should fail if the Pushgateway rejects the metrics", func() {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusBadRequest)
+			}))
+			defer server.Close()
+
+			Expect(PushToGateway(server.URL, "gonut", pushMetrics)).ToNot(Succeed())
+		})
+	})
+})
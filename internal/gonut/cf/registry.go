@@ -0,0 +1,164 @@
+// Copyright © 2019 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cf
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/homeport/pina-golada/pkg/files"
+	"github.com/homeport/pina-golada/pkg/files/paths"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// AppDefinitionFile is the name of the file that declares a user-defined
+// sample app in its registry directory
+const AppDefinitionFile = "app.yml"
+
+var appNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+// AppDefinition describes a user-defined sample app, for example:
+//
+//	caption: Spring Music
+//	buildpack: java_buildpack
+//	stack: cflinuxfs4
+//	aliases: [music]
+//	probes: [ping, task]
+//	status-code: 200
+//	health-check-endpoint: /actuator/health
+//
+// The app files are located next to the definition file, unless a source
+// in form of a git URL is configured.
+type AppDefinition struct {
+	Name       string   `yaml:"-"`
+	Path       string   `yaml:"-"`
+	Caption    string   `yaml:"caption"`
+	Buildpack  string   `yaml:"buildpack"`
+	Stack      string   `yaml:"stack"`
+	Aliases    []string `yaml:"aliases"`
+	Source     string   `yaml:"source"`
+	Probes     []string `yaml:"probes"`
+	StatusCode int      `yaml:"status-code"`
+
+	// HealthCheckEndpoint is the path of the diagnostic endpoint that is used
+	// for http health checks
+	HealthCheckEndpoint string `yaml:"health-check-endpoint"`
+}
+
+// RegistryPath returns the default location of the user-defined sample apps
+func RegistryPath() string {
+	return filepath.Join(HomeDir(), ".gonut", "apps")
+}
+
+// LoadRegistry reads the definitions of all user-defined sample apps, which
+// are the sub-directories containing a definition file. A missing registry
+// directory is treated like an empty one.
+func LoadRegistry(path string) ([]AppDefinition, error) {
+	entries, err := os.ReadDir(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	var result []AppDefinition
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		definitionPath := filepath.Join(path, entry.Name(), AppDefinitionFile)
+		if _, err := os.Stat(definitionPath); os.IsNotExist(err) {
+			continue
+		}
+
+		definition, err := LoadAppDefinition(definitionPath)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, *definition)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+
+	return result, nil
+}
+
+// LoadAppDefinition reads the definition file of a user-defined sample app,
+// the name of the app is the name of the directory containing the file
+func LoadAppDefinition(path string) (*AppDefinition, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var definition AppDefinition
+	if err := yaml.UnmarshalStrict(data, &definition); err != nil {
+		return nil, fmt.Errorf("failed to parse sample app definition %s: %w", path, err)
+	}
+
+	definition.Path = filepath.Dir(path)
+	definition.Name = filepath.Base(definition.Path)
+
+	if !appNamePattern.MatchString(definition.Name) {
+		return nil, fmt.Errorf("invalid sample app name %q of %s, only lowercase letters, digits, and hyphens are supported", definition.Name, path)
+	}
+
+	if definition.Caption == "" {
+		definition.Caption = definition.Name
+	}
+
+	if err := validateProbes(definition.Probes); err != nil {
+		return nil, fmt.Errorf("invalid sample app definition %s: %w", path, err)
+	}
+
+	if definition.HealthCheckEndpoint != "" && !strings.HasPrefix(definition.HealthCheckEndpoint, "/") {
+		return nil, fmt.Errorf("invalid sample app definition %s: health check endpoint %q must be an absolute path", path, definition.HealthCheckEndpoint)
+	}
+
+	return &definition, nil
+}
+
+// HasProbe returns whether the probe is listed in the definition
+func (definition AppDefinition) HasProbe(probe string) bool {
+	return contains(definition.Probes, probe)
+}
+
+// LoadFiles returns the app files located next to the definition file,
+// without the definition file itself and the files listed in .cfignore
+func (definition AppDefinition) LoadFiles() (files.Directory, error) {
+	directory := files.NewRootDirectory()
+	if err := loadFromDisk(directory, definition.Path); err != nil {
+		return nil, err
+	}
+
+	directory.DeleteFile(paths.Of(AppDefinitionFile))
+	return directory, nil
+}
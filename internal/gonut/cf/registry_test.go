@@ -0,0 +1,111 @@
+// Copyright © 2019 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cf_test
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/homeport/gonut/internal/gonut/cf"
+	"github.com/homeport/pina-golada/pkg/files/paths"
+)
+
+var _ = Describe("User-defined sample apps", func() {
+	Context("Loading the registry", func() {
+		It("should load all directories with a definition file", func() {
+			definitions, err := LoadRegistry("../../../assets/test/registry/valid")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(definitions).To(HaveLen(2))
+
+			minimal := definitions[0]
+			Expect(minimal.Name).To(Equal("minimal"))
+			Expect(minimal.Caption).To(Equal("minimal"))
+			Expect(minimal.Source).To(Equal("https://github.com/cloudfoundry-samples/test-app"))
+
+			music := definitions[1]
+			Expect(music.Name).To(Equal("spring-music"))
+			Expect(music.Caption).To(Equal("Spring Music"))
+			Expect(music.Buildpack).To(Equal("java_buildpack"))
+			Expect(music.Stack).To(Equal("cflinuxfs4"))
+			Expect(music.Aliases).To(Equal([]string{"music"}))
+			Expect(music.HasProbe(TaskProbe)).To(BeTrue())
+			Expect(music.HasProbe(SSHProbe)).To(BeFalse())
+			Expect(music.StatusCode).To(Equal(200))
+			Expect(music.HealthCheckEndpoint).To(Equal("/actuator/health"))
+		})
+
+		It("should treat a missing registry directory as empty", func() {
+			definitions, err := LoadRegistry("../../../assets/test/registry/missing")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(definitions).To(BeEmpty())
+		})
+
+		It("should fail for app names that cannot be used in application names", func() {
+			_, err := LoadRegistry("../../../assets/test/registry/invalid")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Bad_Name"))
+		})
+
+		It("should fail for health check endpoints that are not absolute paths", func() {
+			_, err := LoadAppDefinition("../../../assets/test/registry/invalid-endpoint/app.yml")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("must be an absolute path"))
+		})
+	})
+
+	Context("Loading the app files", func() {
+		It("should load the app files without the definition file", func() {
+			definition, err := LoadAppDefinition("../../../assets/test/registry/valid/spring-music/app.yml")
+			Expect(err).ToNot(HaveOccurred())
+
+			directory, err := definition.LoadFiles()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(directory.File(paths.Of("manifest.yml"))).ToNot(BeNil())
+			Expect(directory.File(paths.Of(AppDefinitionFile))).To(BeNil())
+		})
+
+		It("should skip ignored files and version control directories", func() {
+			dir, err := os.MkdirTemp("", "gonut-registry")
+			Expect(err).ToNot(HaveOccurred())
+			defer os.RemoveAll(dir)
+
+			path := filepath.Join(dir, "sample")
+			Expect(os.MkdirAll(filepath.Join(path, ".git"), 0755)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(path, ".git", "HEAD"), []byte("ref: refs/heads/main\n"), 0644)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(path, AppDefinitionFile), []byte("caption: Sample\n"), 0644)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(path, CFIgnoreFile), []byte("*.log\n"), 0644)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(path, "build.log"), []byte("log"), 0644)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(path, "index.html"), []byte("<html/>"), 0644)).To(Succeed())
+
+			definition, err := LoadAppDefinition(filepath.Join(path, AppDefinitionFile))
+			Expect(err).ToNot(HaveOccurred())
+
+			directory, err := definition.LoadFiles()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(directory.File(paths.Of("index.html"))).ToNot(BeNil())
+			Expect(directory.File(paths.Of("build.log"))).To(BeNil())
+			Expect(directory.File(paths.Of(".git/HEAD"))).To(BeNil())
+		})
+	})
+})
@@ -31,7 +31,17 @@ import (
 
 // PushReport encapsules details of a Cloud Foundry push command
 type PushReport struct {
-	AppName string
+	Caption         string
+	AppName         string
+	Commit          string
+	Files           int
+	Bytes           int64
+	Lifecycle       string
+	HealthCheckType string
+
+	// Manifest is the effective manifest of the sample app, the values of its
+	// environment variables are masked in the export since they may be secrets
+	Manifest string
 
 	InitStart      time.Time
 	CreatingStart  time.Time
@@ -40,9 +50,35 @@ type PushReport struct {
 	StartingStart  time.Time
 	PushEnd        time.Time
 
+	TaskSubmitted time.Time
+	TaskRunning   time.Time
+	TaskEnd       time.Time
+
+	SSHStart      time.Time
+	SSHEnd        time.Time
+	SSHSkipReason string
+
 	buildpack  *BuildpackDetails
 	stack      *StackDetails
 	StatusCode int
+
+	Invocations []Invocation
+}
+
+// Phase is one of the steps of a Cloud Foundry app push
+type Phase struct {
+	Name     string
+	Duration func(PushReport) time.Duration
+	Start    func(PushReport) time.Time
+}
+
+// Phases lists the steps of a Cloud Foundry app push in order
+var Phases = []Phase{
+	{"ramp-up", PushReport.InitTime, func(r PushReport) time.Time { return r.InitStart }},
+	{"creating", PushReport.CreatingTime, func(r PushReport) time.Time { return r.CreatingStart }},
+	{"uploading", PushReport.UploadingTime, func(r PushReport) time.Time { return r.UploadingStart }},
+	{"staging", PushReport.StagingTime, func(r PushReport) time.Time { return r.StagingStart }},
+	{"starting", PushReport.StartingTime, func(r PushReport) time.Time { return r.StartingStart }},
 }
 
 // InitTime is the time it takes to initialise the Cloud Foundry app push setup
@@ -75,6 +111,27 @@ func (report PushReport) ElapsedTime() time.Duration {
 	return report.PushEnd.Sub(report.InitStart)
 }
 
+// TaskStartLatency is the time it takes from submitting a task until it runs
+func (report PushReport) TaskStartLatency() time.Duration {
+	return report.TaskRunning.Sub(report.TaskSubmitted)
+}
+
+// TaskDuration is the time it takes for a running task to finish
+func (report PushReport) TaskDuration() time.Duration {
+	return report.TaskEnd.Sub(report.TaskRunning)
+}
+
+// HasTaskDetails returns true if the report contains task execution times
+func (report PushReport) HasTaskDetails() bool {
+	return !report.TaskRunning.IsZero() && !report.TaskEnd.IsZero()
+}
+
+// SSHSessionTime is the time it takes to set up a SSH session into the app
+// container and to run a command in it
+func (report PushReport) SSHSessionTime() time.Duration {
+	return report.SSHEnd.Sub(report.SSHStart)
+}
+
 // Buildpack provides the name of the buildpack used (if detectable)
 func (report PushReport) Buildpack() string {
 	if report.buildpack != nil {
@@ -84,6 +141,26 @@ func (report PushReport) Buildpack() string {
 	return "(unknown)"
 }
 
+// BuildpackName provides the plain name of the buildpack used, or an empty
+// string if it is not detectable
+func (report PushReport) BuildpackName() string {
+	if report.buildpack != nil {
+		return report.buildpack.Entity.Name
+	}
+
+	return ""
+}
+
+// StackName provides the plain name of the stack used, or an empty string if
+// it is not detectable
+func (report PushReport) StackName() string {
+	if report.stack != nil {
+		return report.stack.Entity.Name
+	}
+
+	return ""
+}
+
 // Stack provides the name of the stack used (if detectable)
 func (report PushReport) Stack() string {
 	if report.stack != nil {
@@ -154,6 +231,62 @@ func (report *PushReport) Export() yaml.MapSlice {
 		)
 	}
 
+	if report.HasTaskDetails() {
+		result = append(result,
+			yaml.MapItem{Key: "task-start-latency", Value: report.TaskStartLatency()},
+			yaml.MapItem{Key: "task-duration", Value: report.TaskDuration()},
+		)
+	}
+
+	switch {
+	case report.SSHSkipReason != "":
+		result = append(result,
+			yaml.MapItem{Key: "ssh-session", Value: "skipped, " + report.SSHSkipReason},
+		)
+
+	case !report.SSHEnd.IsZero():
+		result = append(result,
+			yaml.MapItem{Key: "ssh-session", Value: report.SSHSessionTime()},
+		)
+	}
+
+	if report.Caption != "" {
+		result = append(result,
+			yaml.MapItem{Key: "app", Value: report.Caption},
+		)
+	}
+
+	if report.Commit != "" {
+		result = append(result,
+			yaml.MapItem{Key: "commit", Value: report.Commit},
+		)
+	}
+
+	if report.Lifecycle != "" {
+		result = append(result,
+			yaml.MapItem{Key: "lifecycle", Value: report.Lifecycle},
+		)
+	}
+
+	if report.HealthCheckType != "" {
+		result = append(result,
+			yaml.MapItem{Key: "health-check", Value: report.HealthCheckType},
+		)
+	}
+
+	if report.Files > 0 {
+		result = append(result,
+			yaml.MapItem{Key: "files", Value: report.Files},
+			yaml.MapItem{Key: "bytes", Value: report.Bytes},
+		)
+	}
+
+	if manifest := MaskManifest(report.Manifest); manifest != "" {
+		result = append(result,
+			yaml.MapItem{Key: "manifest", Value: manifest},
+		)
+	}
+
 	return result
 }
 
@@ -171,6 +304,31 @@ func (report *PushReport) ExportTable() [][]string {
 		case time.Duration:
 			value = bunt.Sprintf("SteelBlue{%v}", HumanReadableDuration(obj))
 
+		case int, int64:
+			// The number of files and their size are shown together in one row
+			switch item.Key {
+			case "files":
+				value = bunt.Sprintf("DarkSeaGreen{%d (%s)}", report.Files, HumanReadableSize(report.Bytes))
+
+			case "bytes":
+				continue
+
+			default:
+				value = bunt.Sprintf("DarkSeaGreen{%v}", obj)
+			}
+
+		case string:
+			// Multi-line values like the manifest continue in the next rows
+			for i, line := range strings.Split(strings.TrimRight(obj, "\n"), "\n") {
+				if i > 0 {
+					key = ""
+				}
+
+				result = append(result, []string{key, bunt.Sprintf("DarkSeaGreen{%s}", line)})
+			}
+
+			continue
+
 		default:
 			value = bunt.Sprintf("DarkSeaGreen{%v}", fmt.Sprintf("%v", obj))
 		}
@@ -180,3 +338,26 @@ func (report *PushReport) ExportTable() [][]string {
 
 	return result
 }
+
+// ExportComparisonTable creates a side by side representation of multiple
+// reports in form of a two-dimensional array, with one column per report
+func ExportComparisonTable(reports []*PushReport) [][]string {
+	header := []string{""}
+	for _, report := range reports {
+		header = append(header, bunt.Sprintf("*%s*", report.Lifecycle))
+	}
+
+	phases := append(append([]Phase{}, Phases...), Phase{"total", PushReport.ElapsedTime, func(r PushReport) time.Time { return r.InitStart }})
+
+	result := [][]string{header}
+	for _, phase := range phases {
+		row := []string{bunt.Sprintf("DimGray{_%s_}", phase.Name)}
+		for _, report := range reports {
+			row = append(row, bunt.Sprintf("SteelBlue{%v}", HumanReadableDuration(phase.Duration(*report))))
+		}
+
+		result = append(result, row)
+	}
+
+	return result
+}
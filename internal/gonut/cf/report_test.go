@@ -27,7 +27,9 @@ import (
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	"gopkg.in/yaml.v2"
 
+	. "github.com/gonvenience/bunt"
 	. "github.com/homeport/gonut/internal/gonut/cf"
 )
 
@@ -112,4 +114,84 @@ var _ = Describe("Cloud Foundry push report", func() {
 			Expect(report.PushEnd).ToNot(BeEquivalentTo(unset))
 		})
 	})
+
+	Context("Export push report details", func() {
+		BeforeEach(func() {
+			SetColorSettings(OFF, OFF)
+		})
+
+		AfterEach(func() {
+			SetColorSettings(AUTO, AUTO)
+		})
+
+		It("should keep the original keys in front of the ones added later", func() {
+			report := createMockReport("../../../assets/test/cf-push/api-2.133.0/push-and-delete.log")
+			report.Caption = "Golang"
+			report.StatusCode = 200
+
+			var keys []interface{}
+			for _, item := range report.Export() {
+				keys = append(keys, item.Key)
+			}
+
+			Expect(keys[:3]).To(Equal([]interface{}{"stack", "buildpack", "statuscode"}))
+			Expect(keys[len(keys)-1]).To(Equal("app"))
+		})
+
+		It("should include the lifecycle if it is set", func() {
+			report := createMockReport("../../../assets/test/cf-push/api-2.133.0/push-and-delete.log")
+			report.Lifecycle = CNBLifecycle
+
+			Expect(report.Export()).To(ContainElement(yaml.MapItem{Key: "lifecycle", Value: "cnb"}))
+		})
+
+		It("should include the reason why the SSH check was skipped", func() {
+			report := createMockReport("../../../assets/test/cf-push/api-2.133.0/push-and-delete.log")
+			report.SSHSkipReason = "SSH is disabled for space dev"
+
+			Expect(report.Export()).To(ContainElement(yaml.MapItem{Key: "ssh-session", Value: "skipped, SSH is disabled for space dev"}))
+		})
+
+		It("should include the number and size of the uploaded files", func() {
+			report := createMockReport("../../../assets/test/cf-push/api-2.133.0/push-and-delete.log")
+			report.Files, report.Bytes = 42, 3*1024*1024
+
+			Expect(report.Export()).To(ContainElement(yaml.MapItem{Key: "files", Value: 42}))
+			Expect(report.Export()).To(ContainElement(yaml.MapItem{Key: "bytes", Value: int64(3 * 1024 * 1024)}))
+
+			table := report.ExportTable()
+			Expect(table).To(ContainElement([]string{"files", "42 (3.0 MiB)"}))
+			Expect(table).ToNot(ContainElement(ContainElement("bytes")))
+		})
+
+		It("should show multi-line values like the manifest in consecutive rows", func() {
+			report := createMockReport("../../../assets/test/cf-push/api-2.133.0/push-and-delete.log")
+			report.Manifest = "applications:\n- name: sample\n  memory: 64M\n"
+
+			table := report.ExportTable()
+			Expect(table).To(ContainElement([]string{"manifest", "applications:"}))
+			Expect(table).To(ContainElement([]string{"", "- name: sample"}))
+			Expect(table).To(ContainElement([]string{"", "  memory: 64M"}))
+		})
+
+		It("should mask the environment variables of the manifest", func() {
+			report := createMockReport("../../../assets/test/cf-push/api-2.133.0/push-and-delete.log")
+			report.Manifest = "applications:\n- name: sample\n  env:\n    TOKEN: secret\n"
+
+			Expect(report.Export()).To(ContainElement(yaml.MapItem{
+				Key:   "manifest",
+				Value: "applications:\n- name: sample\n  env:\n    TOKEN: xxxxx\n",
+			}))
+		})
+
+		It("should create a side by side comparison of multiple reports", func() {
+			start := time.Now()
+			classic := &PushReport{Lifecycle: BuildpackLifecycle, InitStart: start, PushEnd: start.Add(90 * time.Second)}
+			cnb := &PushReport{Lifecycle: CNBLifecycle, InitStart: start, PushEnd: start.Add(2 * time.Minute)}
+
+			table := ExportComparisonTable([]*PushReport{classic, cnb})
+			Expect(table[0]).To(Equal([]string{"", "buildpack", "cnb"}))
+			Expect(table[len(table)-1]).To(Equal([]string{"total", "1 min 30 sec", "2 min"}))
+		})
+	})
 })
@@ -0,0 +1,188 @@
+// Copyright © 2019 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cf
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gonvenience/neat"
+	"github.com/homeport/gonut/internal/gonut/nok"
+	"gopkg.in/yaml.v2"
+)
+
+// Thresholds defines the maximum durations of push phases, a zero duration
+// means that there is no limit
+type Thresholds struct {
+	Staging  time.Duration
+	Starting time.Duration
+	Total    time.Duration
+}
+
+// Merge returns the thresholds with all limits that are set in the override
+// replacing the respective limit
+func (thresholds Thresholds) Merge(override Thresholds) Thresholds {
+	if override.Staging > 0 {
+		thresholds.Staging = override.Staging
+	}
+
+	if override.Starting > 0 {
+		thresholds.Starting = override.Starting
+	}
+
+	if override.Total > 0 {
+		thresholds.Total = override.Total
+	}
+
+	return thresholds
+}
+
+// IsSet returns true if at least one limit is defined
+func (thresholds Thresholds) IsSet() bool {
+	return thresholds.Staging > 0 || thresholds.Starting > 0 || thresholds.Total > 0
+}
+
+// LoadThresholds reads per sample app thresholds from a YAML file, which maps
+// sample app names to limits, for example:
+//
+//	golang:
+//	  staging: 2m
+//	  total: 5m
+func LoadThresholds(path string) (map[string]Thresholds, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var config map[string]map[string]string
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse thresholds file %s: %w", path, err)
+	}
+
+	result := map[string]Thresholds{}
+	for app, limits := range config {
+		thresholds, err := ParseThresholds(limits)
+		if err != nil {
+			return nil, fmt.Errorf("invalid thresholds for %s in %s: %w", app, path, err)
+		}
+
+		result[app] = thresholds
+	}
+
+	return result, nil
+}
+
+// ParseThresholds creates thresholds from a map of phase names to durations
+func ParseThresholds(limits map[string]string) (Thresholds, error) {
+	var thresholds Thresholds
+	for phase, text := range limits {
+		duration, err := time.ParseDuration(text)
+		if err != nil {
+			return thresholds, err
+		}
+
+		switch phase {
+		case "staging":
+			thresholds.Staging = duration
+
+		case "starting":
+			thresholds.Starting = duration
+
+		case "total":
+			thresholds.Total = duration
+
+		default:
+			return thresholds, fmt.Errorf("unsupported phase %s, supported are staging, starting and total", phase)
+		}
+	}
+
+	return thresholds, nil
+}
+
+// CheckThresholds returns an error listing the offending phases and the full
+// timing table in case the push took longer than allowed by the thresholds.
+// The per phase limits are skipped in case the push output could not be
+// parsed into phases, only the total duration is checked then.
+func CheckThresholds(thresholds Thresholds, report *PushReport) error {
+	phases := append([]Phase{}, Phases...)
+	if !report.HasTimeDetails() {
+		thresholds.Staging, thresholds.Starting = 0, 0
+		phases = nil
+	}
+
+	checks := []struct {
+		name     string
+		duration time.Duration
+		limit    time.Duration
+	}{
+		{"staging", report.StagingTime(), thresholds.Staging},
+		{"starting", report.StartingTime(), thresholds.Starting},
+		{"total", report.ElapsedTime(), thresholds.Total},
+	}
+
+	var exceeded []string
+	for _, check := range checks {
+		if check.limit > 0 && check.duration > check.limit {
+			exceeded = append(exceeded, fmt.Sprintf("%s took %s (limit %s)",
+				check.name,
+				check.duration.Round(time.Millisecond),
+				check.limit,
+			))
+		}
+	}
+
+	if len(exceeded) == 0 {
+		return nil
+	}
+
+	limits := map[string]time.Duration{}
+	for _, check := range checks {
+		limits[check.name] = check.limit
+	}
+
+	rows := [][]string{{"phase", "duration", "limit"}}
+	for _, phase := range append(phases, Phase{Name: "total", Duration: PushReport.ElapsedTime}) {
+		limit := "-"
+		if limits[phase.Name] > 0 {
+			limit = limits[phase.Name].String()
+		}
+
+		rows = append(rows, []string{
+			phase.Name,
+			phase.Duration(*report).Round(time.Millisecond).String(),
+			limit,
+		})
+	}
+
+	table, err := neat.Table(rows, neat.AlignRight(1, 2))
+	if err != nil {
+		return err
+	}
+
+	return nok.Errorf(
+		fmt.Sprintf("push of %s exceeded the time limit in %d phase(s)", report.Caption, len(exceeded)),
+		"%s\n\n%s",
+		strings.Join(exceeded, "\n"),
+		table,
+	)
+}
@@ -0,0 +1,95 @@
+// Copyright © 2019 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cf_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/homeport/gonut/internal/gonut/cf"
+	"github.com/homeport/gonut/internal/gonut/nok"
+)
+
+var _ = Describe("Push duration thresholds", func() {
+	Context("Loading thresholds", func() {
+		It("should load per sample app thresholds from a YAML file", func() {
+			dir, err := os.MkdirTemp("", "gonut-test")
+			Expect(err).ToNot(HaveOccurred())
+			defer os.RemoveAll(dir)
+
+			path := filepath.Join(dir, "thresholds.yml")
+			Expect(os.WriteFile(path, []byte("golang:\n  staging: 2m\n  total: 5m\n"), 0644)).To(Succeed())
+
+			thresholds, err := LoadThresholds(path)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(thresholds).To(HaveKeyWithValue("golang", Thresholds{Staging: 2 * time.Minute, Total: 5 * time.Minute}))
+		})
+
+		It("should fail for unsupported phases", func() {
+			_, err := ParseThresholds(map[string]string{"uploading": "1m"})
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should merge overrides into the general thresholds", func() {
+			general := Thresholds{Staging: time.Minute, Total: 3 * time.Minute}
+			Expect(general.Merge(Thresholds{Total: 5 * time.Minute})).To(Equal(Thresholds{Staging: time.Minute, Total: 5 * time.Minute}))
+		})
+	})
+
+	Context("Checking reports", func() {
+		It("should accept pushes within the limits", func() {
+			Expect(CheckThresholds(Thresholds{Staging: 15 * time.Second}, mockTimedReport("Golang", 10*time.Second))).To(Succeed())
+		})
+
+		It("should list the offending phases and the timing table", func() {
+			err := CheckThresholds(Thresholds{Staging: 5 * time.Second, Total: 40 * time.Second}, mockTimedReport("Golang", 10*time.Second))
+			Expect(err).To(HaveOccurred())
+
+			var details *nok.ErrorWithDetails
+			Expect(errors.As(err, &details)).To(BeTrue())
+			Expect(details.Caption).To(ContainSubstring("2 phase(s)"))
+			Expect(details.Details).To(ContainSubstring("staging took 10s (limit 5s)"))
+			Expect(details.Details).To(ContainSubstring("total took 50s (limit 40s)"))
+			Expect(details.Details).To(ContainSubstring("ramp-up"))
+		})
+
+		It("should skip the per phase limits if the push output could not be parsed into phases", func() {
+			start := time.Now()
+			report := &PushReport{Caption: "Golang", InitStart: start, PushEnd: start.Add(30 * time.Second)}
+			Expect(report.HasTimeDetails()).To(BeFalse())
+
+			Expect(CheckThresholds(Thresholds{Staging: time.Second, Starting: time.Second}, report)).To(Succeed())
+
+			err := CheckThresholds(Thresholds{Staging: time.Second, Total: 20 * time.Second}, report)
+			Expect(err).To(HaveOccurred())
+
+			var details *nok.ErrorWithDetails
+			Expect(errors.As(err, &details)).To(BeTrue())
+			Expect(details.Details).To(ContainSubstring("total took 30s (limit 20s)"))
+			Expect(details.Details).ToNot(ContainSubstring("staging"))
+		})
+	})
+})
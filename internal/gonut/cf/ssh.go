@@ -0,0 +1,90 @@
+// Copyright © 2019 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cf
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/homeport/gonut/internal/gonut/nok"
+)
+
+// SSHCheck returns a post push check that runs a command inside the container
+// of the pushed application using `cf ssh`. In case SSH is disabled for the
+// space or the application, the check is skipped and the reason is noted in
+// the report.
+func SSHCheck() PostPushCheck {
+	return func(updates chan string, appName string, report *PushReport) error {
+		config, err := getCloudFoundryConfig()
+		if err != nil {
+			return nok.Errorf(
+				fmt.Sprintf("failed to check SSH access to application %s", appName),
+				err.Error(),
+			)
+		}
+
+		if !config.SpaceFields.AllowSSH {
+			report.SSHSkipReason = fmt.Sprintf("SSH is disabled for space %s", config.SpaceFields.Name)
+			return nil
+		}
+
+		app, err := getApp(appName)
+		if err != nil {
+			return nok.Errorf(
+				fmt.Sprintf("failed to check SSH access to application %s", appName),
+				err.Error(),
+			)
+		}
+
+		if !app.Entity.EnableSSH {
+			report.SSHSkipReason = fmt.Sprintf("SSH is disabled for application %s", appName)
+			return nil
+		}
+
+		report.SSHStart = time.Now()
+		output, err := cf(updates, "ssh", appName, "-c", "echo $INSTANCE_INDEX")
+		report.SSHEnd = time.Now()
+
+		if err != nil {
+			return nok.Errorf(
+				fmt.Sprintf("failed to run command via SSH in application %s", appName),
+				output,
+			)
+		}
+
+		if index := lastLine(output); index != "0" {
+			return nok.Errorf(
+				fmt.Sprintf("unexpected SSH command output of application %s", appName),
+				"Expected the instance index 0 to be returned, but got: %s",
+				output,
+			)
+		}
+
+		return nil
+	}
+}
+
+// lastLine returns the last non-empty line of the provided text
+func lastLine(text string) string {
+	lines := strings.Split(strings.TrimSpace(text), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
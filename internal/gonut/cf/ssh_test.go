@@ -0,0 +1,138 @@
+// Copyright © 2019 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cf
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/homeport/gonut/internal/gonut/nok"
+)
+
+var _ = Describe("SSH check", func() {
+	var restore []func()
+
+	fake := func(responses ...fakeResponse) string {
+		dir, cleanup := installFakeCF(responses...)
+		restore = append(restore, cleanup)
+		return dir
+	}
+
+	// configure writes a cf CLI configuration with the given SSH setting of
+	// the targeted space into a temporary CF_HOME
+	configure := func(allowSSH bool) {
+		home, err := os.MkdirTemp("", "gonut-cf-home")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(os.MkdirAll(filepath.Join(home, ".cf"), 0755)).To(Succeed())
+		Expect(os.WriteFile(
+			filepath.Join(home, ".cf", "config.json"),
+			[]byte(fmt.Sprintf(`{"SpaceFields":{"GUID":"space-guid","Name":"gonut","AllowSSH":%t}}`, allowSSH)),
+			0644,
+		)).To(Succeed())
+
+		previous, ok := os.LookupEnv("CF_HOME")
+		restore = append(restore, func() {
+			if ok {
+				os.Setenv("CF_HOME", previous)
+			} else {
+				os.Unsetenv("CF_HOME")
+			}
+
+			os.RemoveAll(home)
+		})
+
+		os.Setenv("CF_HOME", home)
+	}
+
+	app := func(enableSSH bool) []fakeResponse {
+		return []fakeResponse{
+			{output: "app-guid\n"},
+			{output: fmt.Sprintf(`{"metadata":{"guid":"app-guid"},"entity":{"name":"gonut-app","enable_ssh":%t}}`, enableSSH)},
+		}
+	}
+
+	AfterEach(func() {
+		for i := len(restore) - 1; i >= 0; i-- {
+			restore[i]()
+		}
+
+		restore = nil
+	})
+
+	It("should skip the check if SSH is disabled for the space", func() {
+		configure(false)
+		dir := fake()
+
+		var report PushReport
+		Expect(SSHCheck()(nil, "gonut-app", &report)).To(Succeed())
+		Expect(report.SSHSkipReason).To(Equal("SSH is disabled for space gonut"))
+		Expect(report.SSHStart.IsZero()).To(BeTrue())
+		Expect(fakeCFCalls(dir)).To(BeEmpty())
+	})
+
+	It("should skip the check if SSH is disabled for the application", func() {
+		configure(true)
+		dir := fake(app(false)...)
+
+		var report PushReport
+		Expect(SSHCheck()(nil, "gonut-app", &report)).To(Succeed())
+		Expect(report.SSHSkipReason).To(Equal("SSH is disabled for application gonut-app"))
+		Expect(fakeCFCalls(dir)).To(Equal([]string{"app gonut-app --guid", "curl /v2/apps/app-guid"}))
+	})
+
+	It("should pass if the command returns the instance index 0", func() {
+		configure(true)
+		dir := fake(append(app(true), fakeResponse{output: "Some SSH banner\n0\n"})...)
+
+		var report PushReport
+		Expect(SSHCheck()(nil, "gonut-app", &report)).To(Succeed())
+		Expect(report.SSHSkipReason).To(BeEmpty())
+		Expect(report.SSHStart.IsZero()).To(BeFalse())
+		Expect(report.SSHEnd).ToNot(BeTemporally("<", report.SSHStart))
+		Expect(fakeCFCalls(dir)).To(ContainElement("ssh gonut-app -c echo $INSTANCE_INDEX"))
+	})
+
+	It("should fail if the command returns something else", func() {
+		configure(true)
+		fake(append(app(true), fakeResponse{output: "$INSTANCE_INDEX\n"})...)
+
+		var report PushReport
+		err := SSHCheck()(nil, "gonut-app", &report)
+		Expect(err).To(HaveOccurred())
+		Expect(err).To(BeAssignableToTypeOf(&nok.ErrorWithDetails{}))
+		Expect(err.(*nok.ErrorWithDetails).Caption).To(ContainSubstring("unexpected SSH command output"))
+	})
+
+	It("should fail if the command cannot be run", func() {
+		configure(true)
+		fake(append(app(true), fakeResponse{output: "Error opening SSH connection\n", exitCode: 1})...)
+
+		var report PushReport
+		err := SSHCheck()(nil, "gonut-app", &report)
+		Expect(err).To(HaveOccurred())
+		Expect(err.(*nok.ErrorWithDetails).Details).To(ContainSubstring("Error opening SSH connection"))
+		Expect(report.SSHEnd.IsZero()).To(BeFalse())
+	})
+})
@@ -0,0 +1,155 @@
+// Copyright © 2019 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cf
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// Probes that can be run against a pushed sample app
+const (
+	PingProbe = "ping"
+	TaskProbe = "task"
+	SSHProbe  = "ssh"
+)
+
+// Suite describes a set of sample app pushes, for example:
+//
+//	name: platform acceptance
+//	tests:
+//	- app: golang
+//	  stacks: [cflinuxfs3, cflinuxfs4]
+//	  lifecycle: both
+//	  manifest:
+//	    memory: 256M
+//	    instances: 2
+//	  probes: [ping, task]
+//	  thresholds:
+//	    staging: 2m
+type Suite struct {
+	Name  string      `yaml:"name"`
+	Tests []SuiteTest `yaml:"tests"`
+}
+
+// SuiteTest describes the pushes of one sample app, unset fields fall back
+// to the push settings of the command line
+type SuiteTest struct {
+	App         string            `yaml:"app"`
+	Stacks      []string          `yaml:"stacks"`
+	Buildpacks  []string          `yaml:"buildpacks"`
+	Lifecycle   string            `yaml:"lifecycle"`
+	HealthCheck string            `yaml:"health-check"`
+	Manifest    ManifestOverrides `yaml:"manifest"`
+	Probes      []string          `yaml:"probes"`
+	Thresholds  map[string]string `yaml:"thresholds"`
+}
+
+// LoadSuite reads and validates a suite file
+func LoadSuite(path string) (*Suite, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var suite Suite
+	if err := yaml.UnmarshalStrict(data, &suite); err != nil {
+		return nil, fmt.Errorf("failed to parse suite file %s: %w", path, err)
+	}
+
+	if suite.Name == "" {
+		suite.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+
+	if len(suite.Tests) == 0 {
+		return nil, fmt.Errorf("suite file %s does not contain any tests", path)
+	}
+
+	for i, test := range suite.Tests {
+		if err := test.validate(); err != nil {
+			return nil, fmt.Errorf("invalid test #%d in suite file %s: %w", i+1, path, err)
+		}
+
+		// Vars files are relative to the suite file
+		for j, varsFile := range test.Manifest.VarsFiles {
+			if varsFile = ExpandHomeDir(varsFile); !filepath.IsAbs(varsFile) {
+				suite.Tests[i].Manifest.VarsFiles[j] = filepath.Join(filepath.Dir(path), varsFile)
+			}
+		}
+	}
+
+	return &suite, nil
+}
+
+func (test SuiteTest) validate() error {
+	if test.App == "" {
+		return fmt.Errorf("no app specified")
+	}
+
+	switch test.Lifecycle {
+	case "", BuildpackLifecycle, CNBLifecycle, "both":
+	default:
+		return fmt.Errorf("unsupported lifecycle %q, supported lifecycles are %s, %s, and both", test.Lifecycle, BuildpackLifecycle, CNBLifecycle)
+	}
+
+	switch test.HealthCheck {
+	case "", PortHealthCheck, ProcessHealthCheck, HTTPHealthCheck, "all":
+	default:
+		return fmt.Errorf("unsupported health check %q, supported health checks are %s, %s, %s, and all", test.HealthCheck, PortHealthCheck, ProcessHealthCheck, HTTPHealthCheck)
+	}
+
+	if err := validateProbes(test.Probes); err != nil {
+		return err
+	}
+
+	if err := test.Manifest.Validate(); err != nil {
+		return err
+	}
+
+	_, err := test.ParsedThresholds()
+	return err
+}
+
+// HasProbe returns whether the probe is listed in the test
+func (test SuiteTest) HasProbe(probe string) bool {
+	return contains(test.Probes, probe)
+}
+
+// ParsedThresholds returns the push duration limits of the test
+func (test SuiteTest) ParsedThresholds() (Thresholds, error) {
+	return ParseThresholds(test.Thresholds)
+}
+
+func validateProbes(probes []string) error {
+	for _, probe := range probes {
+		switch probe {
+		case PingProbe, TaskProbe, SSHProbe:
+		default:
+			return fmt.Errorf("unsupported probe %q, supported probes are %s, %s, and %s", probe, PingProbe, TaskProbe, SSHProbe)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,97 @@
+// Copyright © 2019 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cf_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/homeport/gonut/internal/gonut/cf"
+)
+
+var _ = Describe("Suite files", func() {
+	Context("Loading a suite file", func() {
+		It("should read all tests of the suite", func() {
+			suite, err := LoadSuite("../../../assets/test/suite/acceptance.yml")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(suite.Name).To(Equal("platform acceptance"))
+			Expect(suite.Tests).To(HaveLen(2))
+
+			golang := suite.Tests[0]
+			Expect(golang.App).To(Equal("golang"))
+			Expect(golang.Stacks).To(Equal([]string{"cflinuxfs3", "cflinuxfs4"}))
+			Expect(golang.Lifecycle).To(Equal("both"))
+			Expect(golang.HasProbe(PingProbe)).To(BeTrue())
+			Expect(golang.HasProbe(TaskProbe)).To(BeTrue())
+			Expect(golang.HasProbe(SSHProbe)).To(BeFalse())
+
+			Expect(golang.Manifest).To(Equal(ManifestOverrides{
+				Memory:    "256M",
+				Disk:      "512M",
+				Instances: 2,
+				Env:       map[string]string{"GOVERSION": "go1.19"},
+				VarsFiles: []string{"../../../assets/test/suite/vars.yml"},
+			}))
+
+			thresholds, err := golang.ParsedThresholds()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(thresholds).To(Equal(Thresholds{Staging: 2 * time.Minute, Total: 5 * time.Minute}))
+
+			dora := suite.Tests[1]
+			Expect(dora.App).To(Equal("assets/dora#https://github.com/cloudfoundry/cf-acceptance-tests"))
+			Expect(dora.Buildpacks).To(Equal([]string{"ruby_buildpack"}))
+			Expect(dora.HealthCheck).To(Equal("http"))
+			Expect(dora.Probes).To(BeNil())
+		})
+
+		It("should use the file name if the suite has no name", func() {
+			suite, err := LoadSuite("../../../assets/test/suite/unnamed.yml")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(suite.Name).To(Equal("unnamed"))
+		})
+
+		It("should fail for unsupported probes", func() {
+			_, err := LoadSuite("../../../assets/test/suite/invalid-probe.yml")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("telnet"))
+		})
+
+		It("should fail for unsupported lifecycles", func() {
+			_, err := LoadSuite("../../../assets/test/suite/invalid-lifecycle.yml")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("docker"))
+		})
+
+		It("should fail for unsupported health checks", func() {
+			_, err := LoadSuite("../../../assets/test/suite/invalid-health-check.yml")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("none"))
+		})
+
+		It("should fail for invalid thresholds", func() {
+			_, err := LoadSuite("../../../assets/test/suite/invalid-threshold.yml")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("uploading"))
+		})
+	})
+})
@@ -0,0 +1,100 @@
+// Copyright © 2019 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cf
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Targeting org and space", func() {
+	var (
+		home    string
+		restore []func()
+	)
+
+	config := `{"Target":"https://api.example.com","OrganizationFields":{"Name":"users-org"},"SpaceFields":{"Name":"users-space"}}`
+
+	BeforeEach(func() {
+		var err error
+		home, err = os.MkdirTemp("", "gonut-cf-home-test")
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(os.MkdirAll(filepath.Join(home, ".cf"), 0700)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(home, ".cf", "config.json"), []byte(config), 0600)).To(Succeed())
+
+		previous, wasSet := os.LookupEnv("CF_HOME")
+		restore = append(restore, func() {
+			if wasSet {
+				os.Setenv("CF_HOME", previous)
+			} else {
+				os.Unsetenv("CF_HOME")
+			}
+		})
+
+		os.Setenv("CF_HOME", home)
+	})
+
+	AfterEach(func() {
+		for i := len(restore) - 1; i >= 0; i-- {
+			restore[i]()
+		}
+
+		restore = nil
+		Expect(os.RemoveAll(home)).To(Succeed())
+	})
+
+	It("should target org and space in a temporary copy of the cf CLI configuration", func() {
+		dir, cleanup := installFakeCF(fakeResponse{output: "OK\n"})
+		restore = append(restore, cleanup)
+
+		restoreCFHome, err := IsolateCFHome()
+		Expect(err).ToNot(HaveOccurred())
+
+		tmp := os.Getenv("CF_HOME")
+		Expect(tmp).ToNot(Equal(home))
+		Expect(os.ReadFile(filepath.Join(tmp, ".cf", "config.json"))).To(BeEquivalentTo(config))
+
+		Expect(TargetOrgAndSpace("other-org", "other-space")).To(Succeed())
+		Expect(fakeCFCalls(dir)).To(Equal([]string{"target -o other-org -s other-space"}))
+
+		cfHomes, err := os.ReadFile(filepath.Join(dir, "cf-homes"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(strings.TrimSpace(string(cfHomes))).To(Equal(tmp))
+
+		restoreCFHome()
+		Expect(os.Getenv("CF_HOME")).To(Equal(home))
+		Expect(tmp).ToNot(BeADirectory())
+		Expect(os.ReadFile(filepath.Join(home, ".cf", "config.json"))).To(BeEquivalentTo(config))
+	})
+
+	It("should fail if there is no cf CLI configuration to copy", func() {
+		Expect(os.RemoveAll(filepath.Join(home, ".cf"))).To(Succeed())
+
+		_, err := IsolateCFHome()
+		Expect(err).To(HaveOccurred())
+		Expect(os.Getenv("CF_HOME")).To(Equal(home))
+	})
+})
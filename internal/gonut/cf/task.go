@@ -0,0 +1,184 @@
+// Copyright © 2019 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cf
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gonvenience/text"
+	"github.com/homeport/gonut/internal/gonut/nok"
+)
+
+// Supported task states as reported by the Cloud Foundry v3 tasks API
+const (
+	TaskPending   = "PENDING"
+	TaskRunning   = "RUNNING"
+	TaskSucceeded = "SUCCEEDED"
+	TaskFailed    = "FAILED"
+)
+
+// TaskTimeout is the maximum time to wait for a task to finish
+var TaskTimeout = 5 * time.Minute
+
+// taskPollInterval is the time between two checks of the task state or logs
+var taskPollInterval = time.Second
+
+// TaskCheck returns a post push check that runs a one-off task using the
+// droplet of the pushed application. The task echoes a random marker, which
+// is expected to show up in the application logs afterwards.
+func TaskCheck() PostPushCheck {
+	return func(updates chan string, appName string, report *PushReport) error {
+		taskName := text.RandomStringWithPrefix("gonut-task-", 20)
+		marker := text.RandomStringWithPrefix("gonut-task-output-", 32)
+
+		appGUID, err := cfAppGUID(appName)
+		if err != nil {
+			return nok.Errorf(
+				fmt.Sprintf("failed to run task for application %s", appName),
+				err.Error(),
+			)
+		}
+
+		report.TaskSubmitted = time.Now()
+		if output, err := cf(updates, "run-task", appName, "--command", "echo "+marker, "--name", taskName); err != nil {
+			return nok.Errorf(
+				fmt.Sprintf("failed to run task for application %s", appName),
+				output,
+			)
+		}
+
+		task, err := waitForTask(appGUID, taskName, report)
+		if err != nil {
+			return nok.Errorf(
+				fmt.Sprintf("failed to run task for application %s", appName),
+				err.Error(),
+			)
+		}
+
+		if task.State == TaskFailed {
+			return nok.Errorf(
+				fmt.Sprintf("task %s of application %s failed", taskName, appName),
+				task.Result.FailureReason,
+			)
+		}
+
+		return verifyTaskOutput(appName, taskName, marker)
+	}
+}
+
+// waitForTask polls the tasks API until the task reaches a final state and
+// notes the timestamps of the state transitions in the report
+func waitForTask(appGUID string, taskName string, report *PushReport) (*TaskDetails, error) {
+	deadline := time.Now().Add(TaskTimeout)
+
+	var lastErr error
+	for time.Now().Before(deadline) {
+		task, err := cfCurlTaskByName(appGUID, taskName)
+		if err != nil {
+			// The task is not necessarily listed right after it was submitted
+			// and single requests can fail temporarily, so keep on trying
+			lastErr = err
+			time.Sleep(taskPollInterval)
+			continue
+		}
+
+		lastErr = nil
+
+		switch task.State {
+		case TaskRunning:
+			if report.TaskRunning.IsZero() {
+				report.TaskRunning = time.Now()
+			}
+
+		case TaskSucceeded, TaskFailed:
+			report.TaskEnd = time.Now()
+			if report.TaskRunning.IsZero() {
+				report.TaskRunning = report.TaskEnd
+			}
+
+			return task, nil
+		}
+
+		time.Sleep(taskPollInterval)
+	}
+
+	if lastErr != nil {
+		return nil, fmt.Errorf("task %s did not finish within %v: %w", taskName, TaskTimeout, lastErr)
+	}
+
+	return nil, fmt.Errorf("task %s did not finish within %v", taskName, TaskTimeout)
+}
+
+// verifyTaskOutput checks the recent application logs for the task output,
+// which might take a moment to show up in the log stream
+func verifyTaskOutput(appName string, taskName string, marker string) error {
+	var logs string
+	for attempt := 0; attempt < 10; attempt++ {
+		var err error
+		if logs, err = cf(nil, "logs", appName, "--recent"); err == nil {
+			if hasTaskOutput(logs, taskName, marker) {
+				return nil
+			}
+		}
+
+		time.Sleep(taskPollInterval)
+	}
+
+	return nok.Errorf(
+		fmt.Sprintf("output of task %s of application %s is missing", taskName, appName),
+		"The task finished successfully, but the expected output %s is not part of the application logs:\n%s",
+		marker,
+		logs,
+	)
+}
+
+// hasTaskOutput returns true if the logs contain a task log line (for
+// example `[APP/TASK/<name>/0] OUT <text>`) with the given text
+func hasTaskOutput(logs string, taskName string, text string) bool {
+	for _, line := range strings.Split(logs, "\n") {
+		if strings.Contains(line, "/TASK/"+taskName+"/") && strings.Contains(line, text) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func cfCurlTaskByName(appGUID string, taskName string) (*TaskDetails, error) {
+	result, err := cf(nil, "curl", fmt.Sprintf("/v3/apps/%s/tasks?names=%s", appGUID, taskName))
+	if err != nil {
+		return nil, err
+	}
+
+	var page TaskPage
+	if err := json.Unmarshal([]byte(result), &page); err != nil {
+		return nil, err
+	}
+
+	if len(page.Resources) == 0 {
+		return nil, fmt.Errorf("failed to find task %s", taskName)
+	}
+
+	return &page.Resources[0], nil
+}
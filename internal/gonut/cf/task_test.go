@@ -0,0 +1,170 @@
+// Copyright © 2019 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cf
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/homeport/gonut/internal/gonut/nok"
+)
+
+var _ = Describe("Task check", func() {
+	var (
+		restore          []func()
+		previousInterval time.Duration
+		previousTimeout  time.Duration
+	)
+
+	taskPage := func(state string, failureReason string) fakeResponse {
+		return fakeResponse{output: `{"resources":[{"name":"gonut-task","state":"` + state + `","result":{"failure_reason":"` + failureReason + `"}}]}`}
+	}
+
+	fake := func(responses ...fakeResponse) string {
+		dir, cleanup := installFakeCF(responses...)
+		restore = append(restore, cleanup)
+		return dir
+	}
+
+	BeforeEach(func() {
+		previousInterval, previousTimeout = taskPollInterval, TaskTimeout
+		taskPollInterval = time.Millisecond
+	})
+
+	AfterEach(func() {
+		taskPollInterval, TaskTimeout = previousInterval, previousTimeout
+		for i := len(restore) - 1; i >= 0; i-- {
+			restore[i]()
+		}
+
+		restore = nil
+	})
+
+	Context("waiting for the task to finish", func() {
+		It("should note the state transitions of a successful task", func() {
+			dir := fake(
+				taskPage(TaskPending, ""),
+				taskPage(TaskRunning, ""),
+				taskPage(TaskSucceeded, ""),
+			)
+
+			var report PushReport
+			task, err := waitForTask("app-guid", "gonut-task", &report)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(task.State).To(Equal(TaskSucceeded))
+			Expect(report.TaskRunning.IsZero()).To(BeFalse())
+			Expect(report.TaskEnd.IsZero()).To(BeFalse())
+			Expect(report.TaskEnd).ToNot(BeTemporally("<", report.TaskRunning))
+			Expect(fakeCFCalls(dir)).To(HaveLen(3))
+		})
+
+		It("should keep on trying if the task is not listed yet or a request fails", func() {
+			fake(
+				fakeResponse{output: `{"resources":[]}`},
+				fakeResponse{output: "Request error", exitCode: 1},
+				taskPage(TaskSucceeded, ""),
+			)
+
+			var report PushReport
+			task, err := waitForTask("app-guid", "gonut-task", &report)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(task.State).To(Equal(TaskSucceeded))
+			Expect(report.TaskRunning).To(Equal(report.TaskEnd))
+		})
+
+		It("should return the task with its failure reason if the task failed", func() {
+			fake(taskPage(TaskFailed, "APP/TASK/gonut-task: Exited with status 127"))
+
+			var report PushReport
+			task, err := waitForTask("app-guid", "gonut-task", &report)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(task.State).To(Equal(TaskFailed))
+			Expect(task.Result.FailureReason).To(ContainSubstring("Exited with status 127"))
+		})
+
+		It("should fail with the last error if the task never shows up", func() {
+			fake()
+			TaskTimeout = 50 * time.Millisecond
+
+			var report PushReport
+			_, err := waitForTask("app-guid", "gonut-task", &report)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("did not finish within"))
+			Expect(err.Error()).To(ContainSubstring("exit status 1"))
+		})
+	})
+
+	Context("running the task check", func() {
+		It("should fail with the exit status if the task failed", func() {
+			fake(
+				fakeResponse{output: "app-guid\n"},
+				fakeResponse{output: "Task has been submitted successfully\n"},
+				taskPage(TaskRunning, ""),
+				taskPage(TaskFailed, "APP/TASK/gonut-task: Exited with status 127"),
+			)
+
+			var report PushReport
+			err := TaskCheck()(nil, "gonut-app", &report)
+			Expect(err).To(HaveOccurred())
+			Expect(err).To(BeAssignableToTypeOf(&nok.ErrorWithDetails{}))
+			Expect(err.(*nok.ErrorWithDetails).Details).To(ContainSubstring("Exited with status 127"))
+			Expect(report.TaskSubmitted.IsZero()).To(BeFalse())
+			Expect(report.TaskEnd.IsZero()).To(BeFalse())
+		})
+
+		It("should fail if submitting the task fails", func() {
+			fake(
+				fakeResponse{output: "app-guid\n"},
+				fakeResponse{output: "Task feature is disabled\n", exitCode: 1},
+			)
+
+			var report PushReport
+			err := TaskCheck()(nil, "gonut-app", &report)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("verifying the task output", func() {
+		It("should find the marker in the task log lines", func() {
+			fake(fakeResponse{output: "2022-10-19T10:00:00.00+0000 [APP/TASK/gonut-task/0] OUT gonut-task-output-abc\n"})
+			Expect(verifyTaskOutput("gonut-app", "gonut-task", "gonut-task-output-abc")).To(Succeed())
+		})
+
+		It("should retry until the marker shows up in the logs", func() {
+			fake(
+				fakeResponse{output: "Retrieving logs for app gonut-app\n"},
+				fakeResponse{output: "Request error", exitCode: 1},
+				fakeResponse{output: "[APP/TASK/gonut-task/0] OUT gonut-task-output-abc\n"},
+			)
+
+			Expect(verifyTaskOutput("gonut-app", "gonut-task", "gonut-task-output-abc")).To(Succeed())
+		})
+
+		It("should ignore the marker in log lines of other sources", func() {
+			fake(fakeResponse{output: "[API/0] OUT Created task gonut-task with command echo gonut-task-output-abc\n"})
+			err := verifyTaskOutput("gonut-app", "gonut-task", "gonut-task-output-abc")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("missing"))
+		})
+	})
+})
@@ -0,0 +1,184 @@
+// Copyright © 2019 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gonvenience/bunt"
+	"github.com/gonvenience/neat"
+	"github.com/gonvenience/text"
+	"github.com/homeport/gonut/internal/gonut/cf"
+	"github.com/spf13/cobra"
+)
+
+var cacheOlderThanSetting time.Duration
+
+// cacheCmd represents the cache command
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Manage the local copies of git sample app sources",
+	Long:  "Git based sample apps are cloned into the ~/.gonut directory and updated on every push. Use the sub-commands to inspect, remove, or re-clone these local copies.",
+}
+
+// cacheListCmd represents the cache list command
+var cacheListCmd = &cobra.Command{
+	Use:           "list",
+	Short:         "List the local copies of git sample app sources",
+	Args:          cobra.NoArgs,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE:          cacheListCommandFunc,
+}
+
+// cachePruneCmd represents the cache prune command
+var cachePruneCmd = &cobra.Command{
+	Use:           "prune",
+	Short:         "Remove local copies of git sample app sources that were not used recently",
+	Args:          cobra.NoArgs,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE:          cachePruneCommandFunc,
+}
+
+// cacheRefreshCmd represents the cache refresh command
+var cacheRefreshCmd = &cobra.Command{
+	Use:           "refresh <url>",
+	Short:         "Clone a git sample app source again",
+	Long:          "Removes the local copy of the git sample app source and clones it again, which recovers from broken local copies. The URL uses the same syntax as the push command.",
+	Example:       "gonut cache refresh go#https://github.com/homeport/gonut-sample-apps@main",
+	Args:          cobra.ExactArgs(1),
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE:          cacheRefreshCommandFunc,
+}
+
+func init() {
+	rootCmd.AddCommand(cacheCmd)
+	cacheCmd.AddCommand(cacheListCmd, cachePruneCmd, cacheRefreshCmd)
+
+	cachePruneCmd.Flags().DurationVar(&cacheOlderThanSetting, "older-than", 30*24*time.Hour, "Remove local copies that were not used within the given duration")
+}
+
+func cacheListCommandFunc(cmd *cobra.Command, args []string) error {
+	entries, err := cf.ListCache(cf.CacheDir())
+	if err != nil {
+		return fmt.Errorf("failed to list local copies in %s: %w", cf.CacheDir(), err)
+	}
+
+	if len(entries) == 0 {
+		bunt.Println("There are no local copies of git sample app sources yet.")
+		return nil
+	}
+
+	var total int64
+	for _, entry := range entries {
+		total += entry.Size
+	}
+
+	content, err := neat.Table(cacheTable(entries), neat.AlignRight(3))
+	if err != nil {
+		return err
+	}
+
+	neat.Box(os.Stdout, bunt.Sprintf("*%s* in _%s_ (%s)", text.Plural(len(entries), "local copy", "local copies"), cf.CacheDir(), cf.HumanReadableSize(total)), strings.NewReader(content))
+	return nil
+}
+
+func cachePruneCommandFunc(cmd *cobra.Command, args []string) error {
+	removed, err := cf.PruneCache(cf.CacheDir(), time.Now().Add(-cacheOlderThanSetting))
+	if err != nil {
+		return fmt.Errorf("failed to prune local copies in %s: %w", cf.CacheDir(), err)
+	}
+
+	if len(removed) == 0 {
+		bunt.Printf("There are no local copies that were not used within the last %s.\n", cf.HumanReadableDuration(cacheOlderThanSetting))
+		return nil
+	}
+
+	var total int64
+	for _, entry := range removed {
+		bunt.Printf("Removed _%s_ (last used %s)\n", cacheName(entry), entry.LastUsed.Local().Format("2006-01-02 15:04"))
+		total += entry.Size
+	}
+
+	bunt.Printf("Freed *%s* by removing *%s*.\n", cf.HumanReadableSize(total), text.Plural(len(removed), "local copy", "local copies"))
+	return nil
+}
+
+func cacheRefreshCommandFunc(cmd *cobra.Command, args []string) error {
+	source, ok := parseSampleAppSource(args[0])
+	if !ok {
+		return fmt.Errorf("%s is not a valid git URL", cf.RedactURL(args[0]))
+	}
+
+	commit, err := cf.RefreshSampleAppRef(source.rootURL, source.ref, source.localPath)
+	if err != nil {
+		return err
+	}
+
+	bunt.Printf("Cloned _%s_ into _%s_ at commit *%s*.\n", cf.RedactURL(source.rootURL), source.localPath, commit)
+	return nil
+}
+
+func cacheTable(entries []cf.CacheEntry) [][]string {
+	result := [][]string{{
+		bunt.Sprint("*local copy*"),
+		bunt.Sprint("*url*"),
+		bunt.Sprint("*commit*"),
+		bunt.Sprint("*size*"),
+		bunt.Sprint("*last used*"),
+	}}
+
+	for _, entry := range entries {
+		commit := entry.Commit
+		switch {
+		case entry.Broken:
+			commit = bunt.Sprint("Crimson{broken}")
+
+		case len(commit) > 8:
+			commit = commit[:8]
+		}
+
+		result = append(result, []string{
+			cacheName(entry),
+			entry.URL,
+			commit,
+			cf.HumanReadableSize(entry.Size),
+			bunt.Sprintf("DimGray{%s}", entry.LastUsed.Local().Format("2006-01-02 15:04")),
+		})
+	}
+
+	return result
+}
+
+// cacheName returns the path of the local copy relative to the cache directory
+func cacheName(entry cf.CacheEntry) string {
+	if name, err := filepath.Rel(cf.CacheDir(), entry.Path); err == nil {
+		return name
+	}
+
+	return entry.Path
+}
@@ -23,6 +23,10 @@ func init() {
 }
 
 func cleanUp(cmd *cobra.Command, args []string) error {
+	if err := applyTarget(); err != nil {
+		return err
+	}
+
 	apps, err := cf.GetApps()
 	if err != nil {
 		return err
@@ -39,11 +43,12 @@ func cleanUp(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// getGonutApps returns the apps that were pushed by gonut, which names start
+// with the prefix followed by a dash (see randomAppName)
 func getGonutApps(apps []cf.AppDetails, prefix string) []cf.AppDetails {
 	gonutApps := apps[:0]
 	for _, app := range apps {
-
-		if strings.HasPrefix(app.Entity.Name, prefix) {
+		if strings.HasPrefix(app.Entity.Name, prefix+"-") {
 			gonutApps = append(gonutApps, app)
 		}
 	}
@@ -0,0 +1,63 @@
+// Copyright © 2019 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/homeport/gonut/internal/gonut/cf"
+)
+
+var _ = Describe("Cleanup", func() {
+	apps := func(names ...string) []cf.AppDetails {
+		result := make([]cf.AppDetails, len(names))
+		for i, name := range names {
+			result[i].Entity.Name = name
+		}
+
+		return result
+	}
+
+	names := func(apps []cf.AppDetails) []string {
+		var result []string
+		for _, app := range apps {
+			result = append(result, app.Entity.Name)
+		}
+
+		return result
+	}
+
+	It("should only select apps that start with the prefix and a dash", func() {
+		selected := getGonutApps(apps("smoke-golang-app-x", "smoketest", "smoke", "my-smoke-app", "smoke-"), "smoke")
+		Expect(names(selected)).To(Equal([]string{"smoke-golang-app-x", "smoke-"}))
+	})
+
+	It("should reject application name prefixes that match too many apps", func() {
+		for _, prefix := range []string{"a", "ab", "  ", "smoke-"} {
+			Expect(validateAppNamePrefix(prefix)).ToNot(Succeed(), prefix)
+			Expect(validateConfig("config.yml", &cf.Config{AppNamePrefix: prefix})).ToNot(Succeed(), prefix)
+		}
+
+		Expect(validateAppNamePrefix("app")).To(Succeed())
+		Expect(validateConfig("config.yml", &cf.Config{AppNamePrefix: "smoke"})).To(Succeed())
+	})
+})
@@ -0,0 +1,248 @@
+// Copyright © 2019 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/homeport/gonut/internal/gonut/cf"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// pushFlagAnnotation marks the push flags, which default values can be set
+// using the configuration file or GONUT_* environment variables
+const pushFlagAnnotation = "gonut_push_flag"
+
+// outputFlags are the push flags that belong into the outputs section of the
+// configuration file, since they define where push results are sent to
+var outputFlags = []string{
+	"junit-file",
+	"prometheus-file",
+	"pushgateway-url",
+	"otlp-endpoint",
+	"trace-file",
+	"webhook",
+	"webhook-template",
+}
+
+var configSetting string
+
+// targetConfig contains the configured Cloud Foundry target overrides
+var targetConfig cf.TargetConfig
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&configSetting, "config", "", "Configuration file with push setting defaults (default ~/.gonut/config.yml)")
+	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		return applyConfig(cmd, args)
+	}
+}
+
+// applyConfig sets the push flags that were not used on the command line
+// based on the GONUT_* environment variables and the configuration file,
+// which results in the precedence: flag, environment, file, built-in default
+func applyConfig(cmd *cobra.Command, args []string) error {
+	config, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	if prefix := lookupSetting("app-name-prefix", config.AppNamePrefix); prefix != "" {
+		if err := validateAppNamePrefix(prefix); err != nil {
+			return err
+		}
+
+		GonutAppPrefix = prefix
+	}
+
+	targetConfig = cf.TargetConfig{
+		CFHome: cf.ExpandHomeDir(lookupSetting("cf-home", config.Target.CFHome)),
+		Org:    lookupSetting("org", config.Target.Org),
+		Space:  lookupSetting("space", config.Target.Space),
+	}
+
+	if targetConfig.CFHome != "" {
+		if err := os.Setenv("CF_HOME", targetConfig.CFHome); err != nil {
+			return err
+		}
+	}
+
+	var flagErr error
+	cmd.Flags().VisitAll(func(flag *pflag.Flag) {
+		if flagErr != nil || flag.Changed || flag.Annotations[pushFlagAnnotation] == nil {
+			return
+		}
+
+		source := envName(flag.Name)
+		values, ok := lookupEnv(flag.Name)
+		if !ok {
+			source = "configuration file"
+			values, ok = config.Setting(flag.Name)
+		}
+
+		for _, value := range values {
+			if err := flag.Value.Set(value); err != nil {
+				flagErr = fmt.Errorf("invalid value %q for %s setting in %s: %w", value, flag.Name, source, err)
+				return
+			}
+		}
+	})
+
+	return flagErr
+}
+
+// loadConfig loads the configuration file, which is optional unless it was
+// explicitly specified using the flag or the environment variable
+func loadConfig() (*cf.Config, error) {
+	path, explicit := configSetting, configSetting != ""
+	if !explicit {
+		if env, ok := os.LookupEnv(envName("config")); ok && env != "" {
+			path, explicit = env, true
+		} else {
+			path = cf.ConfigPath()
+		}
+	}
+
+	config, err := cf.LoadConfig(path)
+	switch {
+	case os.IsNotExist(err) && !explicit:
+		return &cf.Config{}, nil
+
+	case err != nil:
+		return nil, fmt.Errorf("failed to load configuration file %s: %w", path, err)
+	}
+
+	return config, validateConfig(path, config)
+}
+
+// validateConfig makes sure that all configured settings exist, so that a
+// typo does not go unnoticed
+func validateConfig(path string, config *cf.Config) error {
+	var unknown []string
+	for _, name := range config.SettingNames() {
+		if pushCmd.PersistentFlags().Lookup(name) == nil {
+			unknown = append(unknown, name)
+		}
+	}
+
+	for name := range config.Outputs {
+		if !contains(outputFlags, name) {
+			unknown = append(unknown, "outputs/"+name)
+		}
+	}
+
+	if len(unknown) > 0 {
+		return fmt.Errorf("configuration file %s contains unknown settings: %s", path, strings.Join(unknown, ", "))
+	}
+
+	if config.AppNamePrefix != "" {
+		if err := validateAppNamePrefix(config.AppNamePrefix); err != nil {
+			return fmt.Errorf("configuration file %s contains an invalid setting: %w", path, err)
+		}
+	}
+
+	return nil
+}
+
+// minAppNamePrefixLength is the minimum length of the application name prefix,
+// since the cleanup command deletes all apps that start with the prefix
+const minAppNamePrefixLength = 3
+
+// validateAppNamePrefix makes sure that the application name prefix is unlikely
+// to match apps that were not pushed by gonut
+func validateAppNamePrefix(prefix string) error {
+	if len(strings.TrimSpace(prefix)) < minAppNamePrefixLength {
+		return fmt.Errorf("app-name-prefix %q is too short, it needs at least %d characters", prefix, minAppNamePrefixLength)
+	}
+
+	if strings.HasSuffix(prefix, "-") {
+		return fmt.Errorf("app-name-prefix %q must not end with a dash, it is added automatically", prefix)
+	}
+
+	return nil
+}
+
+// restoreTarget removes the temporary cf CLI configuration that is used to
+// target the configured org and space, it is nil as long as there is none
+var restoreTarget func()
+
+// applyTarget targets the configured org and space, if any. The target is
+// changed in a temporary copy of the cf CLI configuration, so that the target
+// of the user is left untouched once gonut is done.
+func applyTarget() error {
+	if targetConfig.Org == "" && targetConfig.Space == "" {
+		return nil
+	}
+
+	if restoreTarget == nil {
+		restore, err := cf.IsolateCFHome()
+		if err != nil {
+			return err
+		}
+
+		restoreTarget = restore
+	}
+
+	return cf.TargetOrgAndSpace(targetConfig.Org, targetConfig.Space)
+}
+
+// cleanUpTarget removes the temporary cf CLI configuration, if there is one
+func cleanUpTarget() {
+	if restoreTarget != nil {
+		restoreTarget()
+		restoreTarget = nil
+	}
+}
+
+func lookupSetting(name string, configured string) string {
+	if values, ok := lookupEnv(name); ok {
+		return values[0]
+	}
+
+	return configured
+}
+
+func lookupEnv(name string) ([]string, bool) {
+	value, ok := os.LookupEnv(envName(name))
+	if !ok {
+		return nil, false
+	}
+
+	return []string{value}, true
+}
+
+// envName returns the name of the environment variable of the setting, for
+// example GONUT_NO_PING for no-ping
+func envName(name string) string {
+	return "GONUT_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+}
+
+func contains(list []string, value string) bool {
+	for _, entry := range list {
+		if entry == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -21,48 +21,216 @@
 package cmd
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
+	"github.com/gonvenience/bunt"
+	"github.com/homeport/gonut/internal/gonut/cf"
 	"github.com/homeport/gonut/internal/gonut/nok"
 	"github.com/homeport/pina-golada/pkg/files"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
-// extractCmd represents the push command
+var (
+	extractTargetSetting string
+	extractForceSetting  bool
+)
+
+// extractCmd represents the extract command
 var extractCmd = &cobra.Command{
-	Use:   "extract",
+	Use:   "extract [app...]",
 	Short: "Extracts the sample apps to disk",
-	Long:  `Extracts the sample apps to the local disk.`,
-	RunE: func(cmd *cobra.Command, args []string) error {
-		baseDir := "."
-
-		for _, sampleApp := range sampleApps {
-			path := filepath.Join(baseDir, "SampleApps", sampleApp.caption)
-			if err := os.MkdirAll(path, os.FileMode(0755)); err != nil {
-				return err
-			}
-
-			directory, err := sampleApp.assetFunc()
-			if err != nil {
-				return &nok.ErrorWithDetails{
-					Caption: "failed to load sample app assets into memory",
-					Details: err.Error(),
-				}
-			}
-
-			if err := files.WriteToDisk(directory, path, true); err != nil {
-				return &nok.ErrorWithDetails{
-					Caption: "failed to write sample app files to disk",
-					Details: err.Error(),
-				}
-			}
-		}
+	Long: `Extracts the sample apps to the local disk, one directory per sample app.
 
-		return nil
-	},
+Without arguments, all known sample apps are extracted. Like with the push
+command, sample apps can be selected by name, or by git or archive URL. The
+manifest overrides of the push command (memory, disk, instances, env, and vars
+files) as well as the buildpack, stack, and lifecycle are rendered into the
+extracted manifest, so that the result matches exactly what would be pushed.`,
+	Example:       "gonut extract golang --target /tmp/repro --memory 512M --env DEBUG=true",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE:          extractCommandFunc,
 }
 
 func init() {
 	rootCmd.AddCommand(extractCmd)
+
+	extractCmd.Flags().StringVarP(&extractTargetSetting, "target", "t", "SampleApps", "Directory to extract the sample apps into")
+	extractCmd.Flags().BoolVarP(&extractForceSetting, "force", "f", false, "Replace sample app directories that already exist")
+
+	// Use the same manifest overrides and defaults as the push command
+	manifest := pflag.NewFlagSet("manifest", pflag.ContinueOnError)
+	manifest.StringVarP(&buildpackSetting, "buildpack", "b", "", "Specify buildpack for extracted application")
+	manifest.StringVarP(&stackSetting, "stack", "s", "", "Specify stack for extracted application")
+	manifest.StringVarP(&lifecycleSetting, "lifecycle", "l", "buildpack", "Staging lifecycle to be used: buildpack, cnb")
+	addManifestFlags(manifest)
+	manifest.VisitAll(func(flag *pflag.Flag) {
+		_ = manifest.SetAnnotation(flag.Name, pushFlagAnnotation, []string{"true"})
+	})
+
+	extractCmd.Flags().AddFlagSet(manifest)
+}
+
+func extractCommandFunc(cmd *cobra.Command, args []string) error {
+	if err := registerUserApps(); err != nil {
+		return err
+	}
+
+	if len(args) == 0 {
+		args = []string{"all"}
+	}
+
+	switch lifecycleSetting {
+	case cf.BuildpackLifecycle, cf.CNBLifecycle:
+	default:
+		return fmt.Errorf("unsupported lifecycle setting for extract: %s", lifecycleSetting)
+	}
+
+	if stackSetting == "all" {
+		return fmt.Errorf("unsupported stack setting for extract: %s, only one stack can be rendered into the manifest", stackSetting)
+	}
+
+	apps, err := lookUpSampleApps(args)
+	if err != nil {
+		return err
+	}
+
+	if err := loadManifestOverrides(); err != nil {
+		return err
+	}
+
+	// Check all directory names upfront to not leave a partial extract behind
+	paths := make([]string, len(apps))
+	for i, app := range apps {
+		name, err := extractDirName(app)
+		if err != nil {
+			return err
+		}
+
+		paths[i] = filepath.Join(extractTargetSetting, name)
+	}
+
+	for i, app := range apps {
+		if err := extractSampleApp(app, paths[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// extractSampleApp writes the files of the sample app into the given path,
+// including the manifest with the overrides applied
+func extractSampleApp(app *sampleApp, path string) error {
+	_, err := os.Stat(path)
+	exists := err == nil
+	if exists && !extractForceSetting {
+		return nok.Errorf(
+			fmt.Sprintf("failed to extract %s sample app", app.caption),
+			"directory %s already exists, use --force to replace it",
+			path,
+		)
+	}
+
+	overrides, err := extractManifestOverrides(app)
+	if err != nil {
+		return err
+	}
+
+	directory, err := app.assetFunc()
+	if err != nil {
+		return &nok.ErrorWithDetails{
+			Caption: "failed to load sample app assets into memory",
+			Details: err.Error(),
+		}
+	}
+
+	var rendered bool
+	if !overrides.IsEmpty() {
+		appName := fmt.Sprintf("%s-%s", GonutAppPrefix, strings.TrimSuffix(app.appNameInfix, "-"))
+		if _, err := cf.ApplyManifest(directory, appName, overrides); err != nil {
+			return nok.Errorf(
+				fmt.Sprintf("failed to render manifest of %s sample app", app.caption),
+				err.Error(),
+			)
+		}
+
+		rendered = true
+	}
+
+	// Only replace an existing directory once the sample app is ready to be written
+	if exists {
+		if err := os.RemoveAll(path); err != nil {
+			return err
+		}
+	}
+
+	if err := os.MkdirAll(path, os.FileMode(0755)); err != nil {
+		return err
+	}
+
+	if err := files.WriteToDisk(directory, path, true); err != nil {
+		return &nok.ErrorWithDetails{
+			Caption: "failed to write sample app files to disk",
+			Details: err.Error(),
+		}
+	}
+
+	if rendered {
+		bunt.Printf("Extracted *%s* sample app to _%s_ with rendered %s\n", app.caption, path, cf.ManifestFile)
+	} else {
+		bunt.Printf("Extracted *%s* sample app to _%s_\n", app.caption, path)
+	}
+
+	return nil
+}
+
+// extractManifestOverrides returns the overrides for the manifest of the
+// sample app, including the buildpack and stack which the push command
+// passes as flags
+func extractManifestOverrides(app *sampleApp) (cf.ManifestOverrides, error) {
+	overrides := manifestOverrides.Merge(app.manifest)
+
+	overrides.Buildpack = buildpackSetting
+	if lifecycleSetting == cf.CNBLifecycle {
+		buildpack, ok := cnbBuildpack(app)
+		if !ok {
+			return overrides, nok.Errorf(
+				fmt.Sprintf("failed to render manifest of %s sample app", app.caption),
+				"there is no Paketo buildpack for %s",
+				app.buildpack,
+			)
+		}
+
+		overrides.Buildpack = buildpack
+		overrides.Lifecycle = cf.CNBLifecycle
+	}
+
+	overrides.Stack = stackSetting
+	if len(overrides.Stack) == 0 {
+		overrides.Stack = app.defaultStack
+	}
+
+	return overrides, nil
+}
+
+// extractDirName returns the directory name for the sample app, which is the
+// caption without path separators, since remote sample apps include the
+// relative path in their caption
+func extractDirName(app *sampleApp) (string, error) {
+	name := strings.Trim(strings.ReplaceAll(app.caption, "/", "-"), "-")
+	if name == "" || name == "." || name == ".." || strings.ContainsRune(name, filepath.Separator) {
+		return "", nok.Errorf(
+			fmt.Sprintf("failed to extract %s sample app", app.caption),
+			"%q cannot be used as a directory name inside of %s",
+			name,
+			extractTargetSetting,
+		)
+	}
+
+	return name, nil
 }
@@ -0,0 +1,214 @@
+// Copyright © 2019 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/homeport/gonut/internal/gonut/cf"
+	"github.com/homeport/pina-golada/pkg/files"
+	"github.com/homeport/pina-golada/pkg/files/paths"
+	yaml "gopkg.in/yaml.v2"
+)
+
+var _ = Describe("Extract sample apps", func() {
+	var (
+		target  string
+		restore []func()
+	)
+
+	setting := func(restoreFunc func()) {
+		restore = append(restore, restoreFunc)
+	}
+
+	testApp := func(caption string) *sampleApp {
+		return &sampleApp{
+			caption:      caption,
+			buildpack:    "go_buildpack",
+			appNameInfix: "golang-app-",
+			defaultStack: "cflinuxfs4",
+			assetFunc: func() (files.Directory, error) {
+				directory := files.NewRootDirectory()
+				if err := directory.NewFile(paths.Of("main.go")).Write(bytes.NewBufferString("package main")); err != nil {
+					return nil, err
+				}
+
+				return directory, directory.NewFile(paths.Of(cf.ManifestFile)).Write(bytes.NewBufferString(`---
+applications:
+- name: golang-sample-app
+  memory: 64M
+`))
+			},
+		}
+	}
+
+	application := func(path string) map[string]interface{} {
+		data, err := os.ReadFile(filepath.Join(path, cf.ManifestFile))
+		Expect(err).ToNot(HaveOccurred())
+
+		var manifest struct {
+			Applications []map[string]interface{} `yaml:"applications"`
+		}
+
+		Expect(yaml.Unmarshal(data, &manifest)).To(Succeed())
+		Expect(manifest.Applications).To(HaveLen(1))
+		return manifest.Applications[0]
+	}
+
+	BeforeEach(func() {
+		var err error
+		target, err = os.MkdirTemp("", "gonut-extract")
+		Expect(err).ToNot(HaveOccurred())
+
+		previousTarget, previousForce := extractTargetSetting, extractForceSetting
+		setting(func() { extractTargetSetting, extractForceSetting = previousTarget, previousForce })
+
+		previousBuildpack, previousStack, previousLifecycle := buildpackSetting, stackSetting, lifecycleSetting
+		setting(func() {
+			buildpackSetting, stackSetting, lifecycleSetting = previousBuildpack, previousStack, previousLifecycle
+		})
+
+		previousOverrides := manifestOverrides
+		setting(func() { manifestOverrides = previousOverrides })
+
+		extractTargetSetting, extractForceSetting = target, false
+		buildpackSetting, stackSetting, lifecycleSetting = "", "", cf.BuildpackLifecycle
+		manifestOverrides = cf.ManifestOverrides{}
+	})
+
+	AfterEach(func() {
+		for i := len(restore) - 1; i >= 0; i-- {
+			restore[i]()
+		}
+
+		restore = nil
+		Expect(os.RemoveAll(target)).To(Succeed())
+	})
+
+	Context("target directory", func() {
+		It("should use the caption without path separators as the directory name", func() {
+			name, err := extractDirName(testApp("github.com/homeport/gonut/"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(name).To(Equal("github.com-homeport-gonut"))
+		})
+
+		It("should reject directory names outside of the target directory", func() {
+			for _, caption := range []string{"..", ".", "/", ""} {
+				_, err := extractDirName(testApp(caption))
+				Expect(err).To(HaveOccurred(), caption)
+			}
+		})
+
+		It("should write the sample app files into the target directory", func() {
+			path := filepath.Join(target, "golang")
+			Expect(extractSampleApp(testApp("golang"), path)).To(Succeed())
+			Expect(filepath.Join(path, "main.go")).To(BeARegularFile())
+			Expect(filepath.Join(path, cf.ManifestFile)).To(BeARegularFile())
+		})
+	})
+
+	Context("existing directories", func() {
+		var path string
+
+		BeforeEach(func() {
+			path = filepath.Join(target, "golang")
+			Expect(os.MkdirAll(path, 0755)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(path, "previous.txt"), []byte("previous"), 0644)).To(Succeed())
+		})
+
+		It("should not replace them without force", func() {
+			Expect(extractSampleApp(testApp("golang"), path)).ToNot(Succeed())
+			Expect(filepath.Join(path, "previous.txt")).To(BeARegularFile())
+			Expect(filepath.Join(path, "main.go")).ToNot(BeAnExistingFile())
+		})
+
+		It("should replace them with force", func() {
+			extractForceSetting = true
+			Expect(extractSampleApp(testApp("golang"), path)).To(Succeed())
+			Expect(filepath.Join(path, "previous.txt")).ToNot(BeAnExistingFile())
+			Expect(filepath.Join(path, "main.go")).To(BeARegularFile())
+		})
+
+		It("should keep them if the sample app cannot be loaded", func() {
+			extractForceSetting = true
+			app := testApp("golang")
+			app.assetFunc = func() (files.Directory, error) { return nil, errors.New("failed to load") }
+
+			Expect(extractSampleApp(app, path)).ToNot(Succeed())
+			Expect(filepath.Join(path, "previous.txt")).To(BeARegularFile())
+		})
+
+		It("should keep them if the manifest cannot be rendered", func() {
+			extractForceSetting = true
+			manifestOverrides = cf.ManifestOverrides{VarsFiles: []string{filepath.Join(target, "does-not-exist.yml")}}
+
+			Expect(extractSampleApp(testApp("golang"), path)).ToNot(Succeed())
+			Expect(filepath.Join(path, "previous.txt")).To(BeARegularFile())
+		})
+	})
+
+	Context("rendering the manifest", func() {
+		It("should render the overrides and the default stack", func() {
+			manifestOverrides = cf.ManifestOverrides{Memory: "256M", Env: map[string]string{"DEBUG": "true"}}
+
+			path := filepath.Join(target, "golang")
+			Expect(extractSampleApp(testApp("golang"), path)).To(Succeed())
+			Expect(application(path)).To(Equal(map[string]interface{}{
+				"name":   "golang-sample-app",
+				"memory": "256M",
+				"env":    map[interface{}]interface{}{"DEBUG": "true"},
+				"stack":  "cflinuxfs4",
+			}))
+		})
+
+		It("should render the buildpack and stack settings", func() {
+			buildpackSetting, stackSetting = "https://github.com/cloudfoundry/go-buildpack", "cflinuxfs3"
+
+			path := filepath.Join(target, "golang")
+			Expect(extractSampleApp(testApp("golang"), path)).To(Succeed())
+			Expect(application(path)).To(HaveKeyWithValue("buildpacks", []interface{}{"https://github.com/cloudfoundry/go-buildpack"}))
+			Expect(application(path)).To(HaveKeyWithValue("stack", "cflinuxfs3"))
+		})
+
+		It("should render the Paketo buildpack for the CNB lifecycle", func() {
+			lifecycleSetting = cf.CNBLifecycle
+
+			path := filepath.Join(target, "golang")
+			Expect(extractSampleApp(testApp("golang"), path)).To(Succeed())
+			Expect(application(path)).To(HaveKeyWithValue("buildpacks", []interface{}{"docker://gcr.io/paketo-buildpacks/go"}))
+			Expect(application(path)).To(HaveKeyWithValue("lifecycle", cf.CNBLifecycle))
+		})
+
+		It("should fail for sample apps without a Paketo buildpack", func() {
+			lifecycleSetting = cf.CNBLifecycle
+			app := testApp("unknown")
+			app.buildpack = "unknown_buildpack"
+
+			Expect(extractSampleApp(app, filepath.Join(target, "unknown"))).ToNot(Succeed())
+		})
+	})
+})
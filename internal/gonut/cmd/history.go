@@ -0,0 +1,231 @@
+// Copyright © 2019 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gonvenience/bunt"
+	"github.com/gonvenience/neat"
+	"github.com/homeport/gonut/internal/gonut/cf"
+	"github.com/spf13/cobra"
+)
+
+var (
+	historyAppSetting    string
+	historyStackSetting  string
+	historyTargetSetting string
+	historyLimitSetting  int
+)
+
+// historyCmd represents the history command
+var historyCmd = &cobra.Command{
+	Use:           "history",
+	Short:         "Show results of previous pushes",
+	Long:          "Lists the results of previous sample app pushes and shows the trend of the staging and total push times.",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE:          historyCommandFunc,
+}
+
+func init() {
+	rootCmd.AddCommand(historyCmd)
+
+	historyCmd.Flags().StringVarP(&historyAppSetting, "app", "a", "", "Only show results of the given sample app")
+	historyCmd.Flags().StringVarP(&historyStackSetting, "stack", "s", "", "Only show results of the given stack")
+	historyCmd.Flags().StringVarP(&historyTargetSetting, "target", "t", "", "Only show results of targets containing the given text")
+	historyCmd.Flags().IntVarP(&historyLimitSetting, "limit", "n", 20, "Number of most recent results to list")
+}
+
+func historyCommandFunc(cmd *cobra.Command, args []string) error {
+	// The user-defined sample apps are only needed to resolve their aliases
+	// in the app filter, so an invalid registry does not prevent the listing
+	if err := registerUserApps(); err != nil {
+		printError(err)
+	}
+
+	entries, err := cf.LoadHistory(cf.HistoryPath())
+	if err != nil {
+		return fmt.Errorf("failed to load history from %s: %w", cf.HistoryPath(), err)
+	}
+
+	entries = filterHistory(entries, historyAppSetting, historyStackSetting, historyTargetSetting)
+	if len(entries) == 0 {
+		bunt.Println("There are no push results in the history yet.")
+		return nil
+	}
+
+	recent := entries
+	if historyLimitSetting > 0 && len(recent) > historyLimitSetting {
+		recent = recent[len(recent)-historyLimitSetting:]
+	}
+
+	content, err := neat.Table(historyTable(recent), neat.AlignRight(5, 6))
+	if err != nil {
+		return err
+	}
+
+	neat.Box(os.Stdout, bunt.Sprintf("Most recent *%d* of *%d* push results", len(recent), len(entries)), strings.NewReader(content))
+
+	content, err = neat.Table(trendTable(entries))
+	if err != nil {
+		return err
+	}
+
+	neat.Box(os.Stdout, "Push time trends", strings.NewReader(content))
+
+	return nil
+}
+
+func filterHistory(entries []cf.HistoryEntry, app string, stack string, target string) []cf.HistoryEntry {
+	// Support the sample app command names and aliases in the app filter
+	if sampleApp := lookUpSampleAppByName(app); sampleApp != nil {
+		app = sampleApp.caption
+	}
+
+	var result []cf.HistoryEntry
+	for _, entry := range entries {
+		if app != "" && !strings.EqualFold(entry.App, app) {
+			continue
+		}
+
+		if stack != "" && entry.Stack != stack {
+			continue
+		}
+
+		if target != "" && !strings.Contains(entry.Target, target) {
+			continue
+		}
+
+		result = append(result, entry)
+	}
+
+	return result
+}
+
+func historyTable(entries []cf.HistoryEntry) [][]string {
+	result := [][]string{{
+		bunt.Sprint("*timestamp*"),
+		bunt.Sprint("*app*"),
+		bunt.Sprint("*stack*"),
+		bunt.Sprint("*target*"),
+		bunt.Sprint("*result*"),
+		bunt.Sprint("*staging*"),
+		bunt.Sprint("*total*"),
+	}}
+
+	for _, entry := range entries {
+		status := bunt.Sprint("DarkSeaGreen{success}")
+		if !entry.Success {
+			status = bunt.Sprint("Crimson{failed}")
+		}
+
+		result = append(result, []string{
+			bunt.Sprintf("DimGray{%s}", entry.Timestamp.Local().Format("2006-01-02 15:04")),
+			entry.App,
+			entry.Stack,
+			entry.Target,
+			status,
+			historyDuration(entry.Phases["staging"]),
+			historyDuration(entry.Total),
+		})
+	}
+
+	return result
+}
+
+func trendTable(entries []cf.HistoryEntry) [][]string {
+	type group struct {
+		app, stack, target string
+		staging, total     []float64
+		runs, failures     int
+	}
+
+	var groups []*group
+	index := map[string]*group{}
+	for _, entry := range entries {
+		key := strings.Join([]string{entry.App, entry.Stack, entry.Target}, "|")
+		if _, ok := index[key]; !ok {
+			index[key] = &group{app: entry.App, stack: entry.Stack, target: entry.Target}
+			groups = append(groups, index[key])
+		}
+
+		g := index[key]
+		g.runs++
+		if !entry.Success {
+			g.failures++
+			continue
+		}
+
+		if staging, ok := entry.Phases["staging"]; ok {
+			g.staging = append(g.staging, staging.Seconds())
+		}
+
+		g.total = append(g.total, entry.Total.Seconds())
+	}
+
+	result := [][]string{{
+		bunt.Sprint("*app*"),
+		bunt.Sprint("*stack*"),
+		bunt.Sprint("*target*"),
+		bunt.Sprint("*runs*"),
+		bunt.Sprint("*staging trend*"),
+		bunt.Sprint("*total trend*"),
+	}}
+
+	for _, g := range groups {
+		runs := fmt.Sprintf("%d", g.runs)
+		if g.failures > 0 {
+			runs = bunt.Sprintf("%d (Crimson{%d failed})", g.runs, g.failures)
+		}
+
+		result = append(result, []string{
+			g.app,
+			g.stack,
+			g.target,
+			runs,
+			trend(g.staging),
+			trend(g.total),
+		})
+	}
+
+	return result
+}
+
+func trend(values []float64) string {
+	if len(values) == 0 {
+		return ""
+	}
+
+	last := time.Duration(values[len(values)-1] * float64(time.Second))
+	return bunt.Sprintf("SteelBlue{%s} DimGray{%s}", cf.Sparkline(values), cf.HumanReadableDuration(last))
+}
+
+func historyDuration(duration time.Duration) string {
+	if duration == 0 {
+		return ""
+	}
+
+	return bunt.Sprintf("SteelBlue{%s}", cf.HumanReadableDuration(duration))
+}
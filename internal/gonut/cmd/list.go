@@ -0,0 +1,182 @@
+// Copyright © 2019 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/gonvenience/bunt"
+	"github.com/gonvenience/neat"
+	"github.com/gonvenience/text"
+	"github.com/homeport/gonut/internal/gonut/cf"
+	"github.com/spf13/cobra"
+)
+
+var listOutputSetting string
+
+// listCmd represents the list command
+var listCmd = &cobra.Command{
+	Use:   "list [app...]",
+	Short: "List the sample apps and whether they can be pushed",
+	Long: `Lists the sample apps together with their buildpack, whether the buildpack is
+installed, enabled, and locked on the current target, which stacks it supports,
+and which of those stacks are installed.`,
+	Example:       "gonut list --output yaml",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE:          listCommandFunc,
+}
+
+func init() {
+	rootCmd.AddCommand(listCmd)
+
+	listCmd.Flags().StringVarP(&listOutputSetting, "output", "o", "table", "List output format: table, json, yaml")
+}
+
+func listCommandFunc(cmd *cobra.Command, args []string) error {
+	if err := registerUserApps(); err != nil {
+		return err
+	}
+
+	if len(args) == 0 {
+		args = []string{"all"}
+	}
+
+	apps, err := lookUpSampleApps(args)
+	if err != nil {
+		return err
+	}
+
+	buildpacks, err := cf.GetBuildpacks()
+	if err != nil {
+		return err
+	}
+
+	stackNames, err := cf.GetStackNames()
+	if err != nil {
+		return err
+	}
+
+	list := make([]cf.Availability, len(apps))
+	for i, app := range apps {
+		list[i] = cf.CheckAvailability(app.caption, app.buildpack, buildpacks, stackNames)
+	}
+
+	switch strings.ToLower(listOutputSetting) {
+	case "json":
+		out, err := neat.NewOutputProcessor(true, true, &neat.DefaultColorSchema).ToJSON(list)
+		if err != nil {
+			return err
+		}
+
+		fmt.Println(out)
+
+	case "yaml":
+		out, err := neat.ToYAMLString(list)
+		if err != nil {
+			return err
+		}
+
+		fmt.Println(out)
+
+	case "table":
+		content, err := neat.Table(availabilityTable(list))
+		if err != nil {
+			return err
+		}
+
+		var pushable int
+		for _, availability := range list {
+			if availability.Pushable() {
+				pushable++
+			}
+		}
+
+		neat.Box(os.Stdout,
+			bunt.Sprintf("*%d* of %s can be pushed", pushable, text.Plural(len(list), "sample app")),
+			strings.NewReader(content),
+		)
+
+	default:
+		return fmt.Errorf("unsupported output format %s, use one of table, json, or yaml", listOutputSetting)
+	}
+
+	return nil
+}
+
+func availabilityTable(list []cf.Availability) [][]string {
+	result := [][]string{{
+		bunt.Sprint("*app*"),
+		bunt.Sprint("*buildpack*"),
+		bunt.Sprint("*installed*"),
+		bunt.Sprint("*enabled*"),
+		bunt.Sprint("*locked*"),
+		bunt.Sprint("*stacks*"),
+	}}
+
+	yesNo := func(value bool, yes string, no string) string {
+		if value {
+			return bunt.Sprintf(yes, "yes")
+		}
+
+		return bunt.Sprintf(no, "no")
+	}
+
+	for _, availability := range list {
+		buildpack := availability.Buildpack
+		installed := yesNo(availability.Installed, "DarkSeaGreen{%s}", "Crimson{%s}")
+		enabled := yesNo(availability.Enabled, "DarkSeaGreen{%s}", "Crimson{%s}")
+		locked := yesNo(availability.Locked, "Gold{%s}", "DimGray{%s}")
+		if availability.AutoDetect() {
+			buildpack = bunt.Sprint("DimGray{_auto-detect_}")
+			installed = bunt.Sprint("DimGray{n/a}")
+			enabled = bunt.Sprint("DimGray{n/a}")
+			locked = bunt.Sprint("DimGray{n/a}")
+		}
+
+		// Stacks that exist on the target are highlighted, the others are dimmed
+		var stacks []string
+		for _, stack := range availability.Stacks {
+			if contains(availability.Available, stack) {
+				stacks = append(stacks, bunt.Sprintf("DarkSeaGreen{%s}", stack))
+			} else {
+				stacks = append(stacks, bunt.Sprintf("DimGray{%s}", stack))
+			}
+		}
+
+		if len(stacks) == 0 {
+			stacks = []string{bunt.Sprint("DimGray{none}")}
+		}
+
+		result = append(result, []string{
+			availability.App,
+			buildpack,
+			installed,
+			enabled,
+			locked,
+			strings.Join(stacks, ", "),
+		})
+	}
+
+	return result
+}
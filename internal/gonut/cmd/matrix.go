@@ -0,0 +1,135 @@
+// Copyright © 2019 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gonvenience/bunt"
+	"github.com/gonvenience/neat"
+	"github.com/homeport/gonut/internal/gonut/cf"
+	"github.com/homeport/gonut/internal/gonut/nok"
+)
+
+// matrixCell contains the outcome of the pushes of one sample app to one stack
+type matrixCell struct {
+	app     *sampleApp
+	stack   string
+	reports []*cf.PushReport
+	err     error
+}
+
+func (cell matrixCell) skipped() bool {
+	return cell.err == nil && len(cell.reports) == 0
+}
+
+func (cell matrixCell) elapsedTime() time.Duration {
+	var total time.Duration
+	for _, report := range cell.reports {
+		total += report.ElapsedTime()
+	}
+
+	return total
+}
+
+// runPushMatrix pushes every sample app to every stack and does not stop in
+// case a push fails. Once all pushes are done, the result is shown in form of
+// a grid with the sample apps as rows and the stacks as columns.
+func runPushMatrix(apps []*sampleApp, stacks []string, lifecycles []string, healthChecks []string) error {
+	cells := make([][]matrixCell, len(apps))
+	for i, app := range apps {
+		for _, stack := range stacks {
+			app.stack = stack
+			reports, err := runSampleAppPushes(app, lifecycles, healthChecks)
+			cells[i] = append(cells[i], matrixCell{app: app, stack: stack, reports: reports, err: err})
+		}
+	}
+
+	if isHumanReadableOutput() {
+		content, err := neat.Table(matrixTable(stacks, cells), neat.AlignRight(0))
+		if err != nil {
+			return err
+		}
+
+		neat.Box(os.Stdout, "Push matrix result", strings.NewReader(content))
+	}
+
+	var failed []string
+	for _, row := range cells {
+		for _, cell := range row {
+			if cell.err != nil {
+				failed = append(failed, fmt.Sprintf("%s sample app on %s: %v", cell.app.caption, stackCaption(cell.stack), cell.err))
+			}
+		}
+	}
+
+	if len(failed) > 0 {
+		return nok.Errorf(
+			fmt.Sprintf("%d of %d sample app pushes failed", len(failed), len(apps)*len(stacks)),
+			strings.Join(failed, "\n\n"),
+		)
+	}
+
+	return nil
+}
+
+func matrixTable(stacks []string, cells [][]matrixCell) [][]string {
+	header := []string{""}
+	for _, stack := range stacks {
+		header = append(header, bunt.Sprintf("*%s*", stackCaption(stack)))
+	}
+
+	result := [][]string{header}
+	for _, row := range cells {
+		if len(row) == 0 {
+			continue
+		}
+
+		line := []string{bunt.Sprintf("DimGray{_%s_}", row[0].app.caption)}
+		for _, cell := range row {
+			switch {
+			case cell.err != nil:
+				line = append(line, bunt.Sprint("Crimson{fail}"))
+
+			case cell.skipped():
+				line = append(line, bunt.Sprint("DimGray{skipped}"))
+
+			default:
+				line = append(line, bunt.Sprintf("DarkSeaGreen{pass} SteelBlue{%s}", cf.HumanReadableDuration(cell.elapsedTime())))
+			}
+		}
+
+		result = append(result, line)
+	}
+
+	return result
+}
+
+func stackCaption(stack string) string {
+	if stack == "" {
+		return "default stack"
+	}
+
+	return stack
+}
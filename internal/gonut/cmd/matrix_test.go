@@ -0,0 +1,122 @@
+// Copyright © 2019 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/gonvenience/bunt"
+	"github.com/homeport/gonut/internal/gonut/cf"
+	"github.com/homeport/pina-golada/pkg/files"
+)
+
+var _ = Describe("Push matrix", func() {
+	var restore []func()
+
+	BeforeEach(func() {
+		bunt.SetColorSettings(bunt.OFF, bunt.OFF)
+		restore = append(restore, func() { bunt.SetColorSettings(bunt.AUTO, bunt.AUTO) })
+
+		previousResults, previousOutput, previousBuildpack := pushResults, outputSetting, buildpackSetting
+		restore = append(restore, func() {
+			pushResults, outputSetting, buildpackSetting = previousResults, previousOutput, previousBuildpack
+		})
+		pushResults, outputSetting, buildpackSetting = nil, "quiet", ""
+	})
+
+	AfterEach(func() {
+		for i := len(restore) - 1; i >= 0; i-- {
+			restore[i]()
+		}
+
+		restore = nil
+	})
+
+	Context("result table", func() {
+		It("should show the sample apps as rows and the stacks as columns", func() {
+			golang := &sampleApp{caption: "Golang"}
+			python := &sampleApp{caption: "Python"}
+
+			report := &cf.PushReport{InitStart: time.Unix(1557837912, 0), PushEnd: time.Unix(1557837972, 0)}
+			cells := [][]matrixCell{
+				{
+					{app: golang, stack: "cflinuxfs3", reports: []*cf.PushReport{report}},
+					{app: golang, stack: "cflinuxfs4", err: errors.New("staging failed")},
+				},
+				{
+					{app: python, stack: "cflinuxfs3"},
+					{app: python, stack: "cflinuxfs4", reports: []*cf.PushReport{report, report}},
+				},
+			}
+
+			Expect(matrixTable([]string{"cflinuxfs3", "cflinuxfs4"}, cells)).To(Equal([][]string{
+				{"", "cflinuxfs3", "cflinuxfs4"},
+				{"Golang", "pass " + cf.HumanReadableDuration(time.Minute), "fail"},
+				{"Python", "skipped", "pass " + cf.HumanReadableDuration(2*time.Minute)},
+			}))
+		})
+
+		It("should name the column of the default stack", func() {
+			Expect(matrixTable([]string{""}, nil)).To(Equal([][]string{{"", "default stack"}}))
+		})
+	})
+
+	Context("running the matrix", func() {
+		// Without buildpack and stack, the CNB lifecycle push gets to loading
+		// the sample app files without the need for a Cloud Foundry target
+		unavailable := func(caption string) *sampleApp {
+			return &sampleApp{
+				caption:   caption,
+				assetFunc: func() (files.Directory, error) { return nil, errors.New("unavailable") },
+			}
+		}
+
+		unsupported := func(caption string) *sampleApp {
+			return &sampleApp{caption: caption, buildpack: "unknown_buildpack"}
+		}
+
+		It("should push every sample app to every stack and fail if any push failed", func() {
+			err := runPushMatrix(
+				[]*sampleApp{unavailable("Golang"), unsupported("Python")},
+				[]string{"", ""},
+				[]string{cf.CNBLifecycle},
+				[]string{""},
+			)
+
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("2 of 4 sample app pushes failed"))
+			Expect(pushResults).To(HaveLen(4))
+		})
+
+		It("should not fail if pushes were only skipped", func() {
+			Expect(runPushMatrix(
+				[]*sampleApp{unsupported("Python")},
+				[]string{""},
+				[]string{cf.CNBLifecycle},
+				[]string{""},
+			)).To(Succeed())
+		})
+	})
+})
@@ -0,0 +1,244 @@
+// Copyright © 2019 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/gonvenience/bunt"
+	"github.com/gonvenience/wrap"
+	"github.com/homeport/gonut/internal/gonut/cf"
+	"github.com/spf13/cobra"
+)
+
+var (
+	monitorIntervalSetting time.Duration
+	monitorKeepSetting     int
+	monitorListenSetting   string
+)
+
+// monitorCmd represents the monitor command
+var monitorCmd = &cobra.Command{
+	Use:   "monitor [app] [app] ...",
+	Short: "Continuously push sample apps",
+	Long: `Pushes the given sample apps in a fixed interval and keeps the most recent
+results in memory. The results are available using a local HTTP endpoint:
+
+  /status   push results in JSON format
+  /metrics  most recent push results in Prometheus text format
+
+A failed push does not stop the monitor. On interrupt, the current cycle is
+finished before the monitor shuts down.`,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE:          monitorCommandFunc,
+}
+
+func init() {
+	rootCmd.AddCommand(monitorCmd)
+
+	monitorCmd.Flags().DurationVarP(&monitorIntervalSetting, "interval", "i", 15*time.Minute, "Time to wait between two push cycles")
+	monitorCmd.Flags().IntVarP(&monitorKeepSetting, "keep", "k", 100, "Number of most recent push results to keep in memory")
+	monitorCmd.Flags().StringVar(&monitorListenSetting, "listen", "localhost:8080", "Address of the HTTP endpoint serving the push results")
+	addPushFlags(monitorCmd.Flags())
+
+	// The monitor always continues after failed pushes
+	_ = monitorCmd.Flags().MarkHidden("matrix")
+}
+
+// monitorState contains the most recent push results of the monitor
+type monitorState struct {
+	sync.RWMutex
+
+	keep      int
+	started   time.Time
+	cycles    int
+	lastCycle time.Time
+	entries   []cf.HistoryEntry
+	metrics   []cf.PushMetrics
+}
+
+// monitorStatus is the JSON document served by the status endpoint
+type monitorStatus struct {
+	Started   time.Time         `json:"started"`
+	Interval  string            `json:"interval"`
+	Cycles    int               `json:"cycles"`
+	LastCycle *time.Time        `json:"lastCycle,omitempty"`
+	Healthy   bool              `json:"healthy"`
+	Results   []cf.HistoryEntry `json:"results"`
+}
+
+func monitorCommandFunc(cmd *cobra.Command, args []string) error {
+	if err := registerUserApps(); err != nil {
+		return err
+	}
+
+	if len(args) == 0 {
+		return wrap.Error(
+			bunt.Errorf("*Valid Arguments:*\n%s", getOptions()),
+			"Please provide a sample app name as argument",
+		)
+	}
+
+	if monitorIntervalSetting <= 0 {
+		return fmt.Errorf("invalid interval %v, it needs to be a positive duration", monitorIntervalSetting)
+	}
+
+	if monitorKeepSetting <= 0 {
+		return fmt.Errorf("invalid number of results to keep %d, it needs to be a positive number", monitorKeepSetting)
+	}
+
+	plan, err := newPushPlan(args)
+	if err != nil {
+		return err
+	}
+
+	state := &monitorState{keep: monitorKeepSetting, started: time.Now()}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/status", state.serveStatus)
+	mux.HandleFunc("/metrics", state.serveMetrics)
+
+	server := &http.Server{Addr: monitorListenSetting, Handler: mux}
+	serverErr := make(chan error, 1)
+	go func() {
+		if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+			serverErr <- err
+		}
+	}()
+
+	if isHumanReadableOutput() {
+		bunt.Printf("Serving push results on *http://%s/status* and *http://%s/metrics*\n", monitorListenSetting, monitorListenSetting)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	for {
+		if err := runMonitorCycle(plan, state); err != nil {
+			printError(err)
+		}
+
+		select {
+		case err := <-serverErr:
+			return fmt.Errorf("failed to serve push results on %s: %w", monitorListenSetting, err)
+
+		case <-ctx.Done():
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			return server.Shutdown(shutdownCtx)
+
+		case <-time.After(monitorIntervalSetting):
+		}
+	}
+}
+
+// runMonitorCycle pushes all sample apps of the plan once, adds the results
+// to the monitor state, and writes them to the configured report destinations
+func runMonitorCycle(plan *pushPlan, state *monitorState) error {
+	runStart, pushResults = time.Now(), nil
+
+	for _, app := range plan.apps {
+		for _, stack := range plan.stacks {
+			app.stack = stack // Empty if flag not set
+			if _, err := runSampleAppPushes(app, plan.lifecycles, plan.healthChecks); err != nil {
+				printError(err)
+			}
+		}
+	}
+
+	state.add(historyEntries(pushResults), pushMetrics(pushResults))
+
+	if isHumanReadableOutput() {
+		bunt.Printf("Finished push cycle, next one starts at *%s*\n",
+			time.Now().Add(monitorIntervalSetting).Format(time.Kitchen),
+		)
+	}
+
+	return writePushResults()
+}
+
+func (state *monitorState) add(entries []cf.HistoryEntry, metrics []cf.PushMetrics) {
+	state.Lock()
+	defer state.Unlock()
+
+	state.cycles++
+	state.lastCycle = time.Now()
+	state.entries = append(state.entries, entries...)
+	state.metrics = append(state.metrics, metrics...)
+
+	if len(state.entries) > state.keep {
+		state.entries = state.entries[len(state.entries)-state.keep:]
+	}
+
+	if len(state.metrics) > state.keep {
+		state.metrics = state.metrics[len(state.metrics)-state.keep:]
+	}
+}
+
+func (state *monitorState) serveStatus(w http.ResponseWriter, r *http.Request) {
+	state.RLock()
+	defer state.RUnlock()
+
+	status := monitorStatus{
+		Started:  state.started,
+		Interval: monitorIntervalSetting.String(),
+		Cycles:   state.cycles,
+		Healthy:  true,
+		Results:  append([]cf.HistoryEntry{}, state.entries...),
+	}
+
+	if !state.lastCycle.IsZero() {
+		lastCycle := state.lastCycle
+		status.LastCycle = &lastCycle
+	}
+
+	// The monitor is healthy if the most recent push of each variation worked
+	for _, metrics := range cf.LatestPushMetrics(state.metrics) {
+		if !metrics.Success {
+			status.Healthy = false
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(status); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (state *monitorState) serveMetrics(w http.ResponseWriter, r *http.Request) {
+	state.RLock()
+	defer state.RUnlock()
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	if err := cf.WritePrometheusMetrics(w, cf.LatestPushMetrics(state.metrics)); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
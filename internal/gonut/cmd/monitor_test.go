@@ -0,0 +1,161 @@
+// Copyright © 2019 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/homeport/gonut/internal/gonut/cf"
+)
+
+var _ = Describe("Monitor", func() {
+	var (
+		state   *monitorState
+		restore []func()
+	)
+
+	metrics := func(app string, success bool, offset int) cf.PushMetrics {
+		return cf.PushMetrics{App: app, Success: success, Timestamp: time.Unix(1557837912+int64(offset), 0)}
+	}
+
+	entry := func(app string) cf.HistoryEntry {
+		return cf.HistoryEntry{App: app}
+	}
+
+	status := func() monitorStatus {
+		recorder := httptest.NewRecorder()
+		state.serveStatus(recorder, httptest.NewRequest(http.MethodGet, "/status", nil))
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(recorder.Header().Get("Content-Type")).To(Equal("application/json"))
+
+		var result monitorStatus
+		Expect(json.Unmarshal(recorder.Body.Bytes(), &result)).To(Succeed())
+		return result
+	}
+
+	scrape := func() string {
+		recorder := httptest.NewRecorder()
+		state.serveMetrics(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		return recorder.Body.String()
+	}
+
+	BeforeEach(func() {
+		state = &monitorState{keep: 3, started: time.Now()}
+
+		previousResults, previousNoHistory, previousOutput := pushResults, noHistorySetting, outputSetting
+		restore = append(restore, func() {
+			pushResults, noHistorySetting, outputSetting = previousResults, previousNoHistory, previousOutput
+		})
+		noHistorySetting, outputSetting = true, "quiet"
+	})
+
+	AfterEach(func() {
+		for i := len(restore) - 1; i >= 0; i-- {
+			restore[i]()
+		}
+
+		restore = nil
+	})
+
+	Context("keeping push results", func() {
+		It("should only keep the most recent results", func() {
+			state.add([]cf.HistoryEntry{entry("a"), entry("b")}, []cf.PushMetrics{metrics("a", true, 0), metrics("b", true, 0)})
+			state.add([]cf.HistoryEntry{entry("c"), entry("d")}, []cf.PushMetrics{metrics("c", true, 1), metrics("d", true, 1)})
+
+			Expect(state.cycles).To(Equal(2))
+			Expect(state.entries).To(Equal([]cf.HistoryEntry{entry("b"), entry("c"), entry("d")}))
+			Expect(state.metrics).To(HaveLen(3))
+			Expect(state.metrics[0].App).To(Equal("b"))
+		})
+
+		It("should only add the results of the current cycle", func() {
+			pushResults = []pushResult{{caption: "previous cycle"}}
+
+			app := &sampleApp{caption: "unknown", buildpack: "unknown_buildpack"}
+			plan := &pushPlan{apps: []*sampleApp{app}, stacks: []string{""}, lifecycles: []string{cf.CNBLifecycle}, healthChecks: []string{""}}
+
+			Expect(runMonitorCycle(plan, state)).To(Succeed())
+			Expect(pushResults).To(HaveLen(1))
+			Expect(pushResults[0].caption).To(Equal("unknown"))
+			Expect(pushResults[0].skipReason).ToNot(BeEmpty())
+
+			// Skipped pushes did not happen, so they are not part of the results
+			Expect(state.cycles).To(Equal(1))
+			Expect(state.entries).To(BeEmpty())
+			Expect(state.metrics).To(BeEmpty())
+		})
+	})
+
+	Context("status endpoint", func() {
+		It("should be healthy before the first cycle", func() {
+			result := status()
+			Expect(result.Healthy).To(BeTrue())
+			Expect(result.Cycles).To(Equal(0))
+			Expect(result.LastCycle).To(BeNil())
+			Expect(result.Results).To(BeEmpty())
+		})
+
+		It("should be unhealthy if the most recent push of a sample app failed", func() {
+			state.add([]cf.HistoryEntry{entry("a"), entry("b")}, []cf.PushMetrics{metrics("a", true, 0), metrics("b", false, 0)})
+
+			result := status()
+			Expect(result.Healthy).To(BeFalse())
+			Expect(result.Cycles).To(Equal(1))
+			Expect(result.LastCycle).ToNot(BeNil())
+			Expect(result.Results).To(HaveLen(2))
+		})
+
+		It("should be healthy again once the failed sample app recovered", func() {
+			state.add([]cf.HistoryEntry{entry("a")}, []cf.PushMetrics{metrics("a", false, 0)})
+			state.add([]cf.HistoryEntry{entry("a")}, []cf.PushMetrics{metrics("a", true, 1)})
+
+			Expect(status().Healthy).To(BeTrue())
+		})
+	})
+
+	Context("metrics endpoint", func() {
+		It("should only serve the most recent result of each sample app", func() {
+			// Only the successful push has a report with the actually used settings
+			succeeded := metrics("a", true, 1)
+			succeeded.Report = &cf.PushReport{HealthCheckType: "port"}
+
+			state.add([]cf.HistoryEntry{entry("a")}, []cf.PushMetrics{metrics("a", false, 0)})
+			state.add([]cf.HistoryEntry{entry("a")}, []cf.PushMetrics{succeeded})
+
+			Expect(status().Healthy).To(BeTrue())
+
+			out := scrape()
+			Expect(out).To(ContainSubstring(`gonut_push_success{app="a",stack="",buildpack="",lifecycle="buildpack",health_check=""} 1`))
+			Expect(out).ToNot(ContainSubstring(`} 0` + "\n"))
+		})
+
+		It("should serve nothing before the first cycle", func() {
+			Expect(scrape()).To(BeEmpty())
+		})
+	})
+})
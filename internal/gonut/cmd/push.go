@@ -22,11 +22,16 @@ package cmd
 
 import (
 	"fmt"
+	"net/http"
 	"net/url"
 	"os"
+	"path/filepath"
+	"regexp"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 
 	"github.com/gonvenience/bunt"
 	"github.com/gonvenience/neat"
@@ -34,6 +39,7 @@ import (
 	"github.com/gonvenience/wrap"
 	"github.com/homeport/gonut/internal/gonut/assets"
 	"github.com/homeport/gonut/internal/gonut/cf"
+	"github.com/homeport/gonut/internal/gonut/nok"
 	"github.com/homeport/pina-golada/pkg/files"
 )
 
@@ -42,111 +48,145 @@ import (
 var GonutAppPrefix = "gonut"
 
 type sampleApp struct {
-	caption       string
-	buildpack     string
-	stack         string
-	command       string
-	aliases       []string
-	appNamePrefix string
-	assetFunc     func() (files.Directory, error)
+	caption      string
+	buildpack    string
+	stack        string
+	command      string
+	appNameInfix string
+	manifest     cf.ManifestOverrides
+	defaultStack string
+	probes       []string
+	statusCode   int
+	endpoint     string
+	assetFunc    func() (files.Directory, error)
+	commitFunc   func() string
+}
+
+// healthCheckEndpoint returns the path of the diagnostic endpoint of the sample
+// app, which is used for http health checks
+func (app *sampleApp) healthCheckEndpoint() string {
+	if app.endpoint == "" {
+		return assets.DefaultHealthCheckEndpoint
+	}
+
+	return app.endpoint
+}
+
+// probeSettings returns whether the ping is skipped, and whether the task and
+// SSH checks run, where the probes of the sample app take precedence over the
+// push settings
+func (app *sampleApp) probeSettings() (noPing bool, taskCheck bool, sshCheck bool) {
+	if app.probes == nil {
+		return noPingSetting, taskCheckSetting, sshCheckSetting
+	}
+
+	return !contains(app.probes, cf.PingProbe), contains(app.probes, cf.TaskProbe), contains(app.probes, cf.SSHProbe)
+}
+
+// randomAppName returns an application name that starts with the gonut app
+// prefix, which is looked up at push time since it can be configured
+func (app *sampleApp) randomAppName() string {
+	return text.RandomStringWithPrefix(fmt.Sprintf("%s-%s", GonutAppPrefix, app.appNameInfix), 32)
 }
 
 var (
-	deleteSetting    string
-	outputSetting    string
-	buildpackSetting string
-	stackSetting     string
-	noPingSetting    bool
+	deleteSetting          string
+	outputSetting          string
+	buildpackSetting       string
+	stackSetting           string
+	lifecycleSetting       string
+	healthCheckSetting     string
+	noPingSetting          bool
+	junitFileSetting       string
+	prometheusFileSetting  string
+	pushgatewaySetting     string
+	otlpEndpointSetting    string
+	traceFileSetting       string
+	noHistorySetting       bool
+	baselineSetting        string
+	maxRegressionSetting   string
+	maxStagingSetting      time.Duration
+	maxStartingSetting     time.Duration
+	maxTotalSetting        time.Duration
+	thresholdsFileSetting  string
+	webhookSettings        []string
+	webhookTemplateSetting string
+	memorySetting          string
+	diskSetting            string
+	instancesSetting       int
+	envSettings            []string
+	varsFileSettings       []string
+	matrixSetting          bool
+	taskCheckSetting       bool
+	sshCheckSetting        bool
 )
 
-var sampleApps = []sampleApp{
-	{
-		caption:       "Golang",
-		command:       "golang",
-		buildpack:     "go_buildpack",
-		aliases:       []string{"go"},
-		appNamePrefix: fmt.Sprintf("%s-golang-app-", GonutAppPrefix),
-		assetFunc:     assets.Provider.GoSampleApp,
-	},
-
-	{
-		caption:       "Python",
-		command:       "python",
-		buildpack:     "python_buildpack",
-		aliases:       []string{},
-		appNamePrefix: fmt.Sprintf("%s-python-app-", GonutAppPrefix),
-		assetFunc:     assets.Provider.PythonSampleApp,
-	},
-
-	{
-		caption:       "PHP",
-		command:       "php",
-		buildpack:     "php_buildpack",
-		aliases:       []string{},
-		appNamePrefix: fmt.Sprintf("%s-php-app-", GonutAppPrefix),
-		assetFunc:     assets.Provider.PHPSampleApp,
-	},
-
-	{
-		caption:       "Staticfile",
-		command:       "staticfile",
-		buildpack:     "staticfile_buildpack",
-		aliases:       []string{"static"},
-		appNamePrefix: fmt.Sprintf("%s-staticfile-app-", GonutAppPrefix),
-		assetFunc:     assets.Provider.StaticfileSampleApp,
-	},
-
-	{
-		caption:       "Swift",
-		command:       "swift",
-		buildpack:     "swift_buildpack",
-		aliases:       []string{},
-		appNamePrefix: fmt.Sprintf("%s-swift-app-", GonutAppPrefix),
-		assetFunc:     assets.Provider.SwiftSampleApp,
-	},
-
-	{
-		caption:       "NodeJS",
-		command:       "nodejs",
-		buildpack:     "nodejs_buildpack",
-		aliases:       []string{"node"},
-		appNamePrefix: fmt.Sprintf("%s-nodejs-app-", GonutAppPrefix),
-		assetFunc:     assets.Provider.NodeJSSampleApp,
-	},
-
-	{
-		caption:       "Ruby",
-		command:       "ruby",
-		buildpack:     "ruby_buildpack",
-		aliases:       []string{},
-		appNamePrefix: fmt.Sprintf("%s-ruby-sinatra-app-", GonutAppPrefix),
-		assetFunc:     assets.Provider.RubySampleApp,
-	},
-
-	{
-		caption:       ".NET",
-		command:       "dotnet",
-		buildpack:     "dotnet-core",
-		aliases:       []string{"net"},
-		appNamePrefix: fmt.Sprintf("%s-dotnet-app-", GonutAppPrefix),
-		assetFunc:     assets.Provider.DotNetSampleApp,
-	},
-
-	{
-		caption:       "Binary",
-		command:       "binary",
-		buildpack:     "binary_buildpack",
-		appNamePrefix: fmt.Sprintf("%s-binary-app-", GonutAppPrefix),
-		assetFunc:     assets.Provider.BinarySampleApp,
-	},
-
-	{
-		caption:       "Java",
-		command:       "java",
-		buildpack:     "java_buildpack",
-		appNamePrefix: fmt.Sprintf("%s-java-app-", GonutAppPrefix),
-		assetFunc:     assets.Provider.JavaSampleApp,
-	},
+// baselines contains the reports to compare the push phase durations to, and
+// maxRegression the allowed increase in percent
+var (
+	baselines     []cf.Baseline
+	maxRegression float64
+)
+
+// thresholds contains the general push duration limits, and appThresholds the
+// limits of individual sample apps
+var (
+	thresholds    cf.Thresholds
+	appThresholds map[string]cf.Thresholds
+)
+
+// webhooks contains the endpoints to notify about failed and recovered pushes
+var webhooks []cf.Webhook
+
+// manifestOverrides contains the settings that are merged into the manifest
+// of each pushed sample app
+var manifestOverrides cf.ManifestOverrides
+
+// paketoBuildpacks maps the classic buildpack names of the sample apps to the
+// corresponding Paketo buildpack references used with the CNB lifecycle
+var paketoBuildpacks = map[string]string{
+	"go_buildpack":         "docker://gcr.io/paketo-buildpacks/go",
+	"python_buildpack":     "docker://gcr.io/paketo-buildpacks/python",
+	"php_buildpack":        "docker://gcr.io/paketo-buildpacks/php",
+	"staticfile_buildpack": "docker://gcr.io/paketo-buildpacks/nginx",
+	"nodejs_buildpack":     "docker://gcr.io/paketo-buildpacks/nodejs",
+	"ruby_buildpack":       "docker://gcr.io/paketo-buildpacks/ruby",
+	"dotnet-core":          "docker://gcr.io/paketo-buildpacks/dotnet-core",
+	"binary_buildpack":     "docker://gcr.io/paketo-buildpacks/procfile",
+	"java_buildpack":       "docker://gcr.io/paketo-buildpacks/java",
+}
+
+// cnbBuildpack returns the buildpack used to push the sample app with the CNB
+// lifecycle, which is either the buildpack flag or the Paketo buildpack that
+// corresponds to the classic buildpack of the sample app
+func cnbBuildpack(app *sampleApp) (string, bool) {
+	if len(buildpackSetting) > 0 || len(app.buildpack) == 0 {
+		return buildpackSetting, true
+	}
+
+	buildpack, ok := paketoBuildpacks[app.buildpack]
+	return buildpack, ok
+}
+
+// newSampleApp creates a sample app to be pushed based on the registered one
+func newSampleApp(app assets.SampleApp) *sampleApp {
+	appNameInfix := app.AppNameInfix
+	if appNameInfix == "" {
+		appNameInfix = app.Name + "-app-"
+	}
+
+	return &sampleApp{
+		caption:      app.Caption,
+		command:      app.Name,
+		buildpack:    app.Buildpack,
+		appNameInfix: appNameInfix,
+		defaultStack: app.Stack,
+		probes:       app.Probes,
+		statusCode:   app.StatusCode,
+		endpoint:     app.HealthCheckEndpoint,
+		assetFunc:    app.Load,
+		commitFunc:   app.Commit,
+	}
 }
 
 // pushCmd represents the push command
@@ -154,7 +194,6 @@ var pushCmd = &cobra.Command{
 	Use:           "push [app]",
 	Short:         "Push a sample app to Cloud Foundry",
 	Long:          "Use pre-installed or remote sample apps to be pushed to a Cloud Foundry instance.",
-	Example:       getOptions(),
 	SilenceUsage:  true,
 	SilenceErrors: true,
 	RunE:          pushCommandFunc,
@@ -162,38 +201,123 @@ var pushCmd = &cobra.Command{
 
 func init() {
 	rootCmd.AddCommand(pushCmd)
+	addPushFlags(pushCmd.PersistentFlags())
+
+	// The example lists the sample apps, which includes the user-defined ones
+	// that are only known after the registry was loaded. Since the help does
+	// not run the pre-run hooks, the registry needs to be loaded here, too.
+	help := pushCmd.HelpFunc()
+	pushCmd.SetHelpFunc(func(cmd *cobra.Command, args []string) {
+		if err := registerUserApps(); err != nil {
+			printError(err)
+		}
 
-	pushCmd.PersistentFlags().StringVarP(&deleteSetting, "delete", "d", "always", "Delete application after push: always, never, on-success")
-	pushCmd.PersistentFlags().StringVarP(&outputSetting, "output", "o", "short", "Push output detail level: quiet, short, full")
-	pushCmd.PersistentFlags().StringVarP(&buildpackSetting, "buildpack", "b", "", "Specify buildpack for pushed application")
-	pushCmd.PersistentFlags().StringVarP(&stackSetting, "stack", "s", "", "Specify stack for pushed application")
-	pushCmd.PersistentFlags().BoolVarP(&noPingSetting, "no-ping", "p", false, "Do not ping application after push")
+		cmd.Example = getOptions()
+		help(cmd, args)
+	})
+}
+
+// addPushFlags registers the flags that control how sample apps are pushed
+func addPushFlags(flags *pflag.FlagSet) {
+	push := pflag.NewFlagSet("push", pflag.ContinueOnError)
+	push.StringVarP(&deleteSetting, "delete", "d", "always", "Delete application after push: always, never, on-success")
+	push.StringVarP(&outputSetting, "output", "o", "short", "Push output detail level: quiet, short, full, json, yaml, junit")
+	push.StringVarP(&buildpackSetting, "buildpack", "b", "", "Specify buildpack for pushed application")
+	push.StringVarP(&stackSetting, "stack", "s", "", "Specify stack for pushed application")
+	push.BoolVarP(&noPingSetting, "no-ping", "p", false, "Do not ping application after push")
+	push.StringVarP(&lifecycleSetting, "lifecycle", "l", "buildpack", "Staging lifecycle to be used: buildpack, cnb, both")
+	push.StringVar(&healthCheckSetting, "health-check", "", "Health check type to be used: port, process, http, all")
+	push.BoolVarP(&matrixSetting, "matrix", "m", false, "Continue after failed pushes and show a sample app by stack result matrix")
+	push.BoolVar(&taskCheckSetting, "task-check", false, "Run a one-off task using the droplet of the pushed application")
+	push.BoolVar(&sshCheckSetting, "ssh-check", false, "Run a command inside the pushed application container using SSH")
+	push.StringVar(&junitFileSetting, "junit-file", "", "Write push results as JUnit XML report to the given file")
+	push.StringVar(&prometheusFileSetting, "prometheus-file", "", "Write push results as Prometheus metrics to the given file")
+	push.StringVar(&pushgatewaySetting, "pushgateway-url", "", "Send push results as Prometheus metrics to the given Pushgateway")
+	push.StringVar(&otlpEndpointSetting, "otlp-endpoint", "", "Send a trace of the push phases to the given OTLP/HTTP endpoint")
+	push.StringVar(&traceFileSetting, "trace-file", "", "Write a trace of the push phases in OTLP/JSON format to the given file")
+	push.BoolVar(&noHistorySetting, "no-history", false, "Do not add the push results to the local history")
+	push.StringVar(&baselineSetting, "baseline", "", "Compare the push phase durations to the given JSON push report")
+	push.StringVar(&maxRegressionSetting, "max-regression", "20%", "Maximum allowed regression of a push phase compared to the baseline")
+	push.DurationVar(&maxStagingSetting, "max-staging", 0, "Fail pushes with a staging phase exceeding the given duration")
+	push.DurationVar(&maxStartingSetting, "max-starting", 0, "Fail pushes with a starting phase exceeding the given duration")
+	push.DurationVar(&maxTotalSetting, "max-total", 0, "Fail pushes taking longer than the given duration in total")
+	push.StringVar(&thresholdsFileSetting, "thresholds-file", "", "YAML file with per sample app push duration limits")
+	push.StringArrayVar(&webhookSettings, "webhook", nil, "Notify the webhook about failed and recovered pushes, use slack=<url> or teams=<url> for chat message formats")
+	push.StringVar(&webhookTemplateSetting, "webhook-template", "", "Go template file rendering the JSON payload of generic webhooks")
+	addManifestFlags(push)
+
+	// Mark the push flags, since their defaults can be configured
+	push.VisitAll(func(flag *pflag.Flag) {
+		_ = push.SetAnnotation(flag.Name, pushFlagAnnotation, []string{"true"})
+	})
+
+	flags.AddFlagSet(push)
+}
+
+// addManifestFlags registers the flags with settings that are merged into the
+// sample app manifest
+func addManifestFlags(flags *pflag.FlagSet) {
+	flags.StringVar(&memorySetting, "memory", "", "Memory limit of the pushed application, e.g. 256M, overrides the sample app manifest")
+	flags.StringVar(&diskSetting, "disk", "", "Disk limit of the pushed application, e.g. 1G, overrides the sample app manifest")
+	flags.IntVar(&instancesSetting, "instances", 0, "Number of instances of the pushed application, overrides the sample app manifest")
+	flags.StringArrayVar(&envSettings, "env", nil, "Environment variable KEY=VALUE of the pushed application, added to the sample app manifest")
+	flags.StringArrayVar(&varsFileSettings, "vars-file", nil, "YAML file with values for ((variables)) in the sample app manifest")
 }
 
 func getOptions() string {
-	options := []string{"file:<path>", "http://<git repo hostpath>", "https://<git repo hostpath>", "all"}
-	for _, sampleApp := range sampleApps {
-		options = append(options, sampleApp.command)
+	options := []string{"file:<path>", "http://<git repo hostpath>", "https://<git repo hostpath>", "ssh://<git repo hostpath>", "git@<host>:<git repo path>", "<file or http(s) URL of a zip, jar, or tar.gz archive>", assets.AllSampleApps}
+	for _, app := range assets.SampleApps.Apps() {
+		options = append(options, app.Name)
 	}
 	return strings.Join(options, "\n")
 }
 
+// lookUpSampleApp returns the sample app with the given name or alias, or a
+// sample app based on the given git URL
+func lookUpSampleApp(nameOrURL string) *sampleApp {
+	if app := lookUpSampleAppByName(nameOrURL); app != nil {
+		return app
+	}
+
+	return lookUpSampleAppByURL(nameOrURL)
+}
+
 func lookUpSampleAppByName(name string) *sampleApp {
-	for _, sampleApp := range sampleApps {
-		if sampleApp.command == name {
-			return &sampleApp
-		}
-		for _, alias := range sampleApp.aliases {
-			if alias == name {
-				return &sampleApp
-			}
-		}
+	if app, ok := assets.SampleApps.Lookup(name); ok {
+		return newSampleApp(app)
 	}
 
 	return nil
 }
 
 func lookUpSampleAppByURL(absoluteURL string) *sampleApp {
+	if app, ok := remoteSampleApp(absoluteURL); ok {
+		return newSampleApp(app)
+	}
+
+	return nil
+}
+
+var (
+	scpLikeURL     = regexp.MustCompile(`^([\w.-]+@[\w.-]+):([^/].*)$`)
+	checksumSuffix = regexp.MustCompile(`@sha256:([0-9a-fA-F]{64})$`)
+)
+
+// sampleAppSource describes where the files of a remote sample app come from
+type sampleAppSource struct {
+	rootURL      string
+	ref          string
+	checksum     string
+	relativePath string
+	localPath    string
+	caption      string
+}
+
+// parseSampleAppSource splits a sample app URL into its parts, which is
+// optionally a relative path, the git, archive, or file URL, and a git ref
+// or the SHA-256 checksum of an archive:
+// <path>#<git repo url>@<ref> or <path>#<archive url>@sha256:<checksum>
+func parseSampleAppSource(absoluteURL string) (sampleAppSource, bool) {
 	absoluteURL = strings.Trim(absoluteURL, "/") // Remove leading and tailing '/' to avoid fault paths
 	rootURL := absoluteURL
 	var relativePath string
@@ -206,26 +330,97 @@ func lookUpSampleAppByURL(absoluteURL string) *sampleApp {
 		rootURL = urlParts[1]
 	}
 
-	// Check URL validity and create sample app structure in case it is valid
-	if u, err := url.ParseRequestURI(rootURL); err == nil {
-		pathSlice := strings.Split(u.Path, "/")
-		caption := pathSlice[len(pathSlice)-1] + "/" + relativePath
+	// Rewrite SCP-like SSH URLs (<user>@<host>:<path>) into the URL form
+	// Example: git@github.com:cloudfoundry-samples/cf-sample-app-nodejs
+	if matches := scpLikeURL.FindStringSubmatch(rootURL); matches != nil {
+		rootURL = "ssh://" + matches[1] + "/" + matches[2]
+	}
 
-		return &sampleApp{
-			caption:       caption,
-			appNamePrefix: fmt.Sprintf("%s-custom-app-", GonutAppPrefix),
-			assetFunc: func() (files.Directory, error) {
-				// Example local path: ~/.gonut/github.com/cloudfoundry/cf-acceptance-tests/
-				localPath := cf.HomeDir() + "/.gonut/" + u.Host + u.Path
-				return cf.LoadSampleAppURL(rootURL, relativePath, localPath)
-			},
+	// Split an optional checksum from the archive URL (<archive url>@sha256:<checksum>)
+	// Example: https://example.org/sample-app.zip@sha256:9f86d08...
+	var checksum string
+	if matches := checksumSuffix.FindStringSubmatch(rootURL); matches != nil {
+		checksum = matches[1]
+		rootURL = strings.TrimSuffix(rootURL, matches[0])
+	}
+
+	u, err := url.ParseRequestURI(rootURL)
+	if err != nil {
+		return sampleAppSource{}, false
+	}
+
+	// Split an optional git ref from the repository path (<git repo url>@<ref>)
+	// Example: github.com/cloudfoundry-samples/cf-sample-app-nodejs@v1.2.0
+	var ref string
+	if u.Scheme != "file" && checksum == "" && !cf.IsArchiveURL(rootURL) {
+		if idx := strings.LastIndex(u.Path, "@"); idx >= 0 {
+			ref = u.Path[idx+1:]
+			u.Path = u.Path[:idx]
+			rootURL = strings.TrimSuffix(rootURL, "@"+ref)
 		}
 	}
 
-	return nil
+	// Local copies are only used for remote git repositories
+	var localPath string
+	if u.Scheme != "file" {
+		if localPath, err = cf.CachePath(u.Host, u.Path); err != nil {
+			return sampleAppSource{}, false
+		}
+	}
+
+	pathSlice := strings.Split(u.Path, "/")
+	caption := pathSlice[len(pathSlice)-1] + "/" + relativePath
+	if ref != "" {
+		caption += "@" + ref
+	}
+
+	return sampleAppSource{
+		rootURL:      rootURL,
+		ref:          ref,
+		checksum:     checksum,
+		relativePath: relativePath,
+		localPath:    localPath,
+		caption:      caption,
+	}, true
+}
+
+// remoteSampleApp creates a sample app based on a git or file URL
+func remoteSampleApp(absoluteURL string) (assets.SampleApp, bool) {
+	source, ok := parseSampleAppSource(absoluteURL)
+	if !ok {
+		return assets.SampleApp{}, false
+	}
+
+	if cf.IsArchiveURL(source.rootURL) || source.checksum != "" {
+		return assets.SampleApp{
+			Name:    "custom",
+			Caption: source.caption,
+			Kind:    assets.RemoteApp,
+			Load: func() (files.Directory, error) {
+				return cf.LoadSampleAppArchive(source.rootURL, source.checksum, source.relativePath)
+			},
+		}, true
+	}
+
+	var commit string
+	return assets.SampleApp{
+		Name:    "custom",
+		Caption: source.caption,
+		Kind:    assets.RemoteApp,
+		Load: func() (files.Directory, error) {
+			directory, sha, err := cf.LoadSampleAppRef(source.rootURL, source.ref, source.relativePath, source.localPath)
+			commit = sha
+			return directory, err
+		},
+		Commit: func() string { return commit },
+	}, true
 }
 
 func pushCommandFunc(cmd *cobra.Command, args []string) error {
+	if err := registerUserApps(); err != nil {
+		return err
+	}
+
 	if len(args) == 0 {
 		return wrap.Error(
 			bunt.Errorf("*Valid Arguments:*\n%s", getOptions()),
@@ -233,74 +428,411 @@ func pushCommandFunc(cmd *cobra.Command, args []string) error {
 		)
 	}
 
+	plan, err := newPushPlan(args)
+	if err != nil {
+		return err
+	}
+
+	pushErr := plan.run()
+	if err := writePushResults(); err != nil {
+		return err
+	}
+
+	return pushErr
+}
+
+// pushPlan contains the sample apps and the variations they are pushed with
+type pushPlan struct {
+	apps         []*sampleApp
+	stacks       []string
+	lifecycles   []string
+	healthChecks []string
+}
+
+// lookUpSampleApps returns the sample apps for the given names, aliases, or
+// URLs, where "all" stands for all known sample apps
+func lookUpSampleApps(args []string) ([]*sampleApp, error) {
 	var apps []*sampleApp
 	for _, arg := range args {
-		if arg == "all" {
-			for i := range sampleApps {
-				apps = append(apps, &sampleApps[i])
+		if arg == assets.AllSampleApps {
+			for _, app := range assets.SampleApps.Apps() {
+				apps = append(apps, newSampleApp(app))
 			}
 
-		} else if app := lookUpSampleAppByName(arg); app != nil {
-			apps = append(apps, app)
-
-		} else if app := lookUpSampleAppByURL(arg); app != nil {
+		} else if app := lookUpSampleApp(arg); app != nil {
 			apps = append(apps, app)
 
 		} else {
-			return fmt.Errorf("could not find %s sample app. Please use an argument from the following list:\n\n%s", arg, getOptions())
+			return nil, fmt.Errorf("could not find %s sample app. Please use an argument from the following list:\n\n%s", arg, getOptions())
 		}
 	}
 
-	for _, app := range apps {
-		switch stackSetting {
-		case "all":
-			stacks, err := cf.GetStackNames()
-			if err != nil {
-				return fmt.Errorf("an error occurred while trying to retrieve a list of installed stacks: %v", err)
+	return apps, nil
+}
+
+// newPushPlan looks up the sample apps for the given arguments and prepares
+// everything based on the push settings
+func newPushPlan(args []string) (*pushPlan, error) {
+	apps, err := lookUpSampleApps(args)
+	if err != nil {
+		return nil, err
+	}
+
+	lifecycles, err := getLifecycles()
+	if err != nil {
+		return nil, err
+	}
+
+	healthChecks, err := getHealthChecks()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := preparePushSettings(); err != nil {
+		return nil, err
+	}
+
+	stacks, err := getStacks()
+	if err != nil {
+		return nil, err
+	}
+
+	return &pushPlan{
+		apps:         apps,
+		stacks:       stacks,
+		lifecycles:   lifecycles,
+		healthChecks: healthChecks,
+	}, nil
+}
+
+// preparePushSettings loads the files referenced by the push settings and
+// targets the configured org and space
+func preparePushSettings() error {
+	if err := loadBaselines(); err != nil {
+		return err
+	}
+
+	if err := loadThresholds(); err != nil {
+		return err
+	}
+
+	if err := loadWebhooks(); err != nil {
+		return err
+	}
+
+	if err := loadManifestOverrides(); err != nil {
+		return err
+	}
+
+	return applyTarget()
+}
+
+func (plan *pushPlan) run() error {
+	if matrixSetting {
+		return runPushMatrix(plan.apps, plan.stacks, plan.lifecycles, plan.healthChecks)
+	}
+
+	for _, app := range plan.apps {
+		for _, stack := range plan.stacks {
+			app.stack = stack // Empty if flag not set
+			if _, err := runSampleAppPushes(app, plan.lifecycles, plan.healthChecks); err != nil {
+				return err
 			}
+		}
+	}
+
+	return nil
+}
+
+func getStacks() ([]string, error) {
+	if stackSetting != "all" {
+		return []string{stackSetting}, nil
+	}
+
+	stacks, err := cf.GetStackNames()
+	if err != nil {
+		return nil, fmt.Errorf("an error occurred while trying to retrieve a list of installed stacks: %v", err)
+	}
+
+	return stacks, nil
+}
+
+func loadBaselines() error {
+	if baselineSetting == "" {
+		return nil
+	}
+
+	var err error
+	if maxRegression, err = cf.ParsePercentage(maxRegressionSetting); err != nil {
+		return err
+	}
+
+	baselines, err = cf.LoadBaselines(baselineSetting)
+	return err
+}
+
+func loadThresholds() error {
+	thresholds = cf.Thresholds{
+		Staging:  maxStagingSetting,
+		Starting: maxStartingSetting,
+		Total:    maxTotalSetting,
+	}
+
+	if thresholdsFileSetting == "" {
+		return nil
+	}
+
+	var err error
+	appThresholds, err = cf.LoadThresholds(thresholdsFileSetting)
+	return err
+}
 
-			for _, stack := range stacks {
-				app.stack = stack
-				if err := runSampleAppPush(app); err != nil {
-					return err
-				}
+func loadWebhooks() error {
+	webhooks = nil
+	for _, setting := range webhookSettings {
+		webhook, err := cf.ParseWebhook(setting)
+		if err != nil {
+			return err
+		}
+
+		if webhook.Format == cf.GenericWebhook && webhookTemplateSetting != "" {
+			if webhook.Template, err = cf.LoadWebhookTemplate(webhookTemplateSetting); err != nil {
+				return fmt.Errorf("failed to load webhook template %s: %w", webhookTemplateSetting, err)
 			}
+		}
+
+		webhooks = append(webhooks, webhook)
+	}
+
+	return nil
+}
+
+func loadManifestOverrides() error {
+	manifestOverrides = cf.ManifestOverrides{
+		Memory:    memorySetting,
+		Disk:      diskSetting,
+		Instances: instancesSetting,
+	}
+
+	for _, setting := range envSettings {
+		name, value, ok := strings.Cut(setting, "=")
+		if !ok {
+			return fmt.Errorf("invalid environment variable %q, use KEY=VALUE", setting)
+		}
+
+		if manifestOverrides.Env == nil {
+			manifestOverrides.Env = map[string]string{}
+		}
+
+		manifestOverrides.Env[name] = value
+	}
+
+	// The push runs in a temporary directory, so relative paths are resolved
+	for _, setting := range varsFileSettings {
+		path, err := filepath.Abs(cf.ExpandHomeDir(setting))
+		if err != nil {
+			return err
+		}
+
+		manifestOverrides.VarsFiles = append(manifestOverrides.VarsFiles, path)
+	}
+
+	return manifestOverrides.Validate()
+}
+
+// getThresholds returns the push duration limits for the sample app, where
+// limits defined for the sample app override the general ones
+func getThresholds(app *sampleApp) cf.Thresholds {
+	result := thresholds
+	for _, name := range []string{app.caption, app.command} {
+		if override, ok := appThresholds[name]; ok && name != "" {
+			result = result.Merge(override)
+		}
+	}
+
+	return result
+}
+
+func getLifecycles() ([]string, error) {
+	switch lifecycleSetting {
+	case cf.BuildpackLifecycle, cf.CNBLifecycle:
+		return []string{lifecycleSetting}, nil
+
+	case "both":
+		return []string{cf.BuildpackLifecycle, cf.CNBLifecycle}, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported lifecycle setting: %s", lifecycleSetting)
+	}
+}
+
+func getHealthChecks() ([]string, error) {
+	switch healthCheckSetting {
+	case "":
+		return []string{""}, nil
+
+	case cf.PortHealthCheck, cf.ProcessHealthCheck, cf.HTTPHealthCheck:
+		return []string{healthCheckSetting}, nil
+
+	case "all":
+		return []string{cf.PortHealthCheck, cf.ProcessHealthCheck, cf.HTTPHealthCheck}, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported health check setting: %s", healthCheckSetting)
+	}
+}
+
+// runSampleAppPushes pushes the sample app once per lifecycle and health check
+// type and prints a side by side comparison in case more than one was used
+func runSampleAppPushes(app *sampleApp, lifecycles []string, healthChecks []string) ([]*cf.PushReport, error) {
+	var reports []*cf.PushReport
+	for _, lifecycle := range lifecycles {
+		var report *cf.PushReport
+		var err error
+
+		if len(healthChecks) == 1 {
+			report, err = runSampleAppPush(app, lifecycle, healthChecks[0])
+		} else {
+			report, err = runHealthCheckMatrix(app, lifecycle, healthChecks)
+		}
+
+		if err != nil {
+			return reports, err
+		}
+
+		if report != nil {
+			reports = append(reports, report)
+		}
+	}
+
+	if len(reports) < 2 {
+		return reports, nil
+	}
+
+	if isHumanReadableOutput() {
+		headline := bunt.Sprintf("Lifecycle comparison of *%s* sample app", app.caption)
+
+		content, err := neat.Table(cf.ExportComparisonTable(reports), neat.AlignRight(0))
+		if err != nil {
+			return reports, err
+		}
+
+		neat.Box(os.Stdout, headline, strings.NewReader(content))
+	}
+
+	return reports, nil
+}
+
+// runHealthCheckMatrix pushes the sample app once per health check type, even
+// if one of them fails, and prints the results in form of a matrix. The first
+// successful report is returned for further comparisons.
+func runHealthCheckMatrix(app *sampleApp, lifecycle string, healthChecks []string) (*cf.PushReport, error) {
+	var (
+		result *cf.PushReport
+		failed []string
+		rows   = [][]string{{"", bunt.Sprint("*result*"), bunt.Sprint("*starting*"), bunt.Sprint("*total*")}}
+	)
+
+	for _, healthCheck := range healthChecks {
+		key := bunt.Sprintf("DimGray{_%s_}", healthCheck)
+
+		report, err := runSampleAppPush(app, lifecycle, healthCheck)
+		switch {
+		case err != nil:
+			failed = append(failed, fmt.Sprintf("%s: %v", healthCheck, err))
+			rows = append(rows, []string{key, bunt.Sprint("Crimson{failed}"), "", ""})
+
+		case report == nil:
+			rows = append(rows, []string{key, bunt.Sprint("DimGray{skipped}"), "", ""})
+
 		default:
-			app.stack = stackSetting // Empty if flag not set
-			if err := runSampleAppPush(app); err != nil {
-				return err
+			if result == nil {
+				result = report
 			}
+
+			rows = append(rows, []string{key,
+				bunt.Sprint("DarkSeaGreen{running}"),
+				bunt.Sprintf("SteelBlue{%s}", cf.HumanReadableDuration(report.StartingTime())),
+				bunt.Sprintf("SteelBlue{%s}", cf.HumanReadableDuration(report.ElapsedTime())),
+			})
+		}
+	}
+
+	if isHumanReadableOutput() {
+		content, err := neat.Table(rows, neat.AlignRight(0))
+		if err != nil {
+			return nil, err
 		}
 
+		neat.Box(os.Stdout,
+			bunt.Sprintf("Health check matrix of *%s* sample app (%s lifecycle)", app.caption, lifecycle),
+			strings.NewReader(content),
+		)
 	}
 
-	return nil
+	if len(failed) > 0 {
+		return nil, nok.Errorf(
+			fmt.Sprintf("%s sample app failed to start with %d of %d health check types", app.caption, len(failed), len(healthChecks)),
+			strings.Join(failed, "\n\n"),
+		)
+	}
+
+	return result, nil
 }
 
-func runSampleAppPush(app *sampleApp) error {
+// skipBaselineComparison warns that the push of the sample app could not be
+// compared to the baseline, which goes to stderr to not interfere with the
+// machine readable output formats
+func skipBaselineComparison(app *sampleApp, reason string) {
+	bunt.Fprintf(os.Stderr, "Skipped baseline comparison of *%s* sample app, because %s.\n",
+		app.caption,
+		reason,
+	)
+}
+
+func runSampleAppPush(app *sampleApp, lifecycle string, healthCheck string) (report *cf.PushReport, err error) {
+	var skipReason string
+	defer func() {
+		recordPushResult(app, lifecycle, healthCheck, report, skipReason, err)
+	}()
+
+	if len(app.stack) == 0 {
+		app.stack = app.defaultStack
+	}
+
 	// Prepare flags for cf push command
-	flags := []string{}
+	var flags []string
 
 	// Check for stack existence
-	if len(buildpackSetting) > 0 {
+	switch {
+	case lifecycle == cf.CNBLifecycle:
+		buildpack, ok := cnbBuildpack(app)
+		if !ok {
+			skipReason = skipSampleAppPush(app, "there is no Paketo buildpack for DarkSeaGreen{%s}", app.buildpack)
+			return nil, nil
+		}
+
+		flags = append(flags, "--lifecycle", cf.CNBLifecycle)
+		if len(buildpack) > 0 {
+			flags = append(flags, "-b", buildpack)
+		}
+
+	case len(buildpackSetting) > 0:
 		app.buildpack = buildpackSetting
 
 		hasBuildpack, err := cf.HasBuildpack(app.buildpack)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		isExternalBuildpack, err := cf.IsExternalBuildpack(app.buildpack)
 		if err != nil {
-			return err
+			return nil, err
 		}
 
 		// Skip sample app push if desired buildpack is unavailable
 		if !hasBuildpack && !isExternalBuildpack {
-			bunt.Printf("Skipping push of *%s* sample app, because there is no DarkSeaGreen{%s} installed.\n",
-				app.caption,
-				app.buildpack,
-			)
-			return nil
+			skipReason = skipSampleAppPush(app, "there is no DarkSeaGreen{%s} installed", app.buildpack)
+			return nil, nil
 		}
 
 		flags = append(flags, "-b", app.buildpack)
@@ -310,21 +842,25 @@ func runSampleAppPush(app *sampleApp) error {
 	if len(app.stack) > 0 {
 		hasStack, err := cf.HasStack(app.stack)
 		if err != nil {
-			return err
+			return nil, err
 		}
 
 		// Skip sample app push if desired stack is unavailable
 		if !hasStack {
-			bunt.Printf("Skipping push of *%s* sample app, because there is no DarkSeaGreen{%s} stack installed.\n",
-				app.caption,
-				app.buildpack,
-			)
-			return nil
+			skipReason = skipSampleAppPush(app, "there is no DarkSeaGreen{%s} stack installed", app.stack)
+			return nil, nil
 		}
 
 		flags = append(flags, "-s", app.stack)
 	}
 
+	if len(healthCheck) > 0 {
+		flags = append(flags, "-u", healthCheck)
+		if healthCheck == cf.HTTPHealthCheck {
+			flags = append(flags, "--endpoint", app.healthCheckEndpoint())
+		}
+	}
+
 	var cleanupSetting cf.AppCleanupSetting
 	switch deleteSetting {
 	case "always":
@@ -337,19 +873,83 @@ func runSampleAppPush(app *sampleApp) error {
 		cleanupSetting = cf.OnSuccess
 
 	default:
-		return fmt.Errorf("unsupported delete setting: %s", deleteSetting)
+		return nil, fmt.Errorf("unsupported delete setting: %s", deleteSetting)
 	}
 
-	appName := text.RandomStringWithPrefix(app.appNamePrefix, 32)
+	appName := app.randomAppName()
 
 	directory, err := app.assetFunc()
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	// Settings of the sample app, e.g. from a suite test, take precedence
+	var manifest string
+	if overrides := manifestOverrides.Merge(app.manifest); !overrides.IsEmpty() {
+		if manifest, err = cf.ApplyManifest(directory, appName, overrides); err != nil {
+			return nil, err
+		}
+
+	} else if manifest, err = cf.ReadManifest(directory); err != nil {
+		return nil, err
+	}
+
+	noPing, taskCheck, sshCheck := app.probeSettings()
+
+	var checks []cf.PostPushCheck
+	if !noPing && app.statusCode != 0 && app.statusCode != http.StatusOK {
+		// The ping of the push itself expects statuscode 200
+		noPing = true
+		checks = append(checks, cf.PingCheck(app.statusCode))
+	}
+
+	if taskCheck {
+		checks = append(checks, cf.TaskCheck())
+	}
+
+	if sshCheck {
+		checks = append(checks, cf.SSHCheck())
+	}
+
+	if len(healthCheck) > 0 {
+		checks = append(checks, cf.RunningCheck())
+	}
+
+	report, err = cf.PushApp(app.caption, appName, directory, flags, cleanupSetting, noPing, checks...)
+	if report != nil {
+		report.Manifest = manifest
+		if app.commitFunc != nil {
+			report.Commit = app.commitFunc()
+		}
 	}
 
-	report, err := cf.PushApp(app.caption, appName, directory, flags, cleanupSetting, noPingSetting)
 	if err != nil {
-		return err
+		return report, err
+	}
+
+	report.Lifecycle = lifecycle
+	report.HealthCheckType = healthCheck
+
+	// Performance gates need to pass before the push is reported as successful
+	if limits := getThresholds(app); limits.IsSet() {
+		if err := cf.CheckThresholds(limits, report); err != nil {
+			return report, err
+		}
+	}
+
+	if baselines != nil {
+		switch baseline := cf.FindBaseline(baselines, report); {
+		case baseline == nil:
+			skipBaselineComparison(app, "the baseline does not contain a push of it")
+
+		case !report.HasTimeDetails():
+			skipBaselineComparison(app, "the push report does not contain timings for all push phases")
+
+		default:
+			if err := cf.CompareToBaseline(*baseline, report, maxRegression); err != nil {
+				return report, err
+			}
+		}
 	}
 
 	switch strings.ToLower(outputSetting) {
@@ -362,10 +962,17 @@ func runSampleAppPush(app *sampleApp) error {
 			cf.HumanReadableDuration(report.ElapsedTime()),
 		)
 
+		if report.SSHSkipReason != "" {
+			bunt.Printf("Skipped SSH check of *%s* sample app, because %s.\n",
+				app.caption,
+				report.SSHSkipReason,
+			)
+		}
+
 	case "json":
 		out, err := neat.NewOutputProcessor(true, true, &neat.DefaultColorSchema).ToJSON(report.Export())
 		if err != nil {
-			return err
+			return nil, err
 		}
 
 		fmt.Println(out)
@@ -373,7 +980,7 @@ func runSampleAppPush(app *sampleApp) error {
 	case "yaml":
 		out, err := neat.ToYAMLString(report.Export())
 		if err != nil {
-			return err
+			return nil, err
 		}
 
 		fmt.Println(out)
@@ -386,11 +993,11 @@ func runSampleAppPush(app *sampleApp) error {
 
 		content, err := neat.Table(report.ExportTable(), neat.AlignRight(0))
 		if err != nil {
-			return err
+			return nil, err
 		}
 
 		neat.Box(os.Stdout, headline, strings.NewReader(content))
 	}
 
-	return nil
+	return report, nil
 }
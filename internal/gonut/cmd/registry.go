@@ -0,0 +1,81 @@
+// Copyright © 2019 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/homeport/gonut/internal/gonut/assets"
+	"github.com/homeport/gonut/internal/gonut/cf"
+)
+
+// userAppsRegistered is set once the user-defined sample apps are registered
+var userAppsRegistered bool
+
+// registerUserApps adds the user-defined sample apps of the registry
+// directory to the sample app registry, so that they can be used exactly
+// like the embedded ones. It is only used by the commands that resolve sample
+// apps, so that an invalid registry does not break the other commands.
+func registerUserApps() error {
+	if userAppsRegistered {
+		return nil
+	}
+
+	definitions, err := cf.LoadRegistry(cf.RegistryPath())
+	if err != nil {
+		return fmt.Errorf("failed to load user-defined sample apps from %s: %w", cf.RegistryPath(), err)
+	}
+
+	for i := range definitions {
+		definition := definitions[i]
+
+		app := assets.SampleApp{
+			Name:       definition.Name,
+			Caption:    definition.Caption,
+			Kind:       assets.UserApp,
+			Buildpack:  definition.Buildpack,
+			Stack:      definition.Stack,
+			Aliases:    definition.Aliases,
+			Probes:     definition.Probes,
+			StatusCode: definition.StatusCode,
+			Load:       definition.LoadFiles,
+
+			HealthCheckEndpoint: definition.HealthCheckEndpoint,
+		}
+
+		if definition.Source != "" {
+			source, ok := remoteSampleApp(definition.Source)
+			if !ok {
+				return fmt.Errorf("user-defined sample app %s in %s has an invalid source %s", definition.Name, definition.Path, definition.Source)
+			}
+
+			app.Load = source.Load
+			app.Commit = source.Commit
+		}
+
+		if err := assets.SampleApps.Register(app); err != nil {
+			return fmt.Errorf("failed to register user-defined sample app from %s: %w", definition.Path, err)
+		}
+	}
+
+	userAppsRegistered = true
+	return nil
+}
@@ -0,0 +1,115 @@
+// Copyright © 2019 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("User-defined sample apps", func() {
+	var (
+		home    string
+		restore []func()
+	)
+
+	BeforeEach(func() {
+		var err error
+		home, err = os.MkdirTemp("", "gonut-home")
+		Expect(err).ToNot(HaveOccurred())
+
+		registry, err := filepath.Abs("../../../assets/test/registry/valid")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(os.MkdirAll(filepath.Join(home, ".gonut"), 0755)).To(Succeed())
+		Expect(os.Symlink(registry, filepath.Join(home, ".gonut", "apps"))).To(Succeed())
+
+		previous := os.Getenv("HOME")
+		restore = append(restore, func() { os.Setenv("HOME", previous) })
+		os.Setenv("HOME", home)
+	})
+
+	AfterEach(func() {
+		for i := len(restore) - 1; i >= 0; i-- {
+			restore[i]()
+		}
+
+		restore = nil
+		Expect(os.RemoveAll(home)).To(Succeed())
+	})
+
+	It("should list the user-defined sample apps in the help of the push command", func() {
+		var out bytes.Buffer
+		rootCmd.SetOut(&out)
+		rootCmd.SetArgs([]string{"push", "--help"})
+		defer func() {
+			rootCmd.SetOut(nil)
+			rootCmd.SetArgs(nil)
+		}()
+
+		Expect(rootCmd.Execute()).To(Succeed())
+		Expect(out.String()).To(ContainSubstring("golang\n"))
+		Expect(out.String()).To(ContainSubstring("spring-music\n"))
+	})
+
+	It("should register the user-defined sample apps only once", func() {
+		Expect(registerUserApps()).To(Succeed())
+		Expect(registerUserApps()).To(Succeed())
+		Expect(lookUpSampleAppByName("music")).ToNot(BeNil())
+	})
+
+	Context("invalid registry", func() {
+		BeforeEach(func() {
+			registry, err := filepath.Abs("../../../assets/test/registry/invalid")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(os.Remove(filepath.Join(home, ".gonut", "apps"))).To(Succeed())
+			Expect(os.Symlink(registry, filepath.Join(home, ".gonut", "apps"))).To(Succeed())
+
+			previous := userAppsRegistered
+			restore = append(restore, func() { userAppsRegistered = previous })
+			userAppsRegistered = false
+		})
+
+		execute := func(args ...string) error {
+			rootCmd.SetOut(&bytes.Buffer{})
+			rootCmd.SetArgs(args)
+			defer func() {
+				rootCmd.SetOut(nil)
+				rootCmd.SetArgs(nil)
+			}()
+
+			return rootCmd.Execute()
+		}
+
+		It("should not affect commands that do not use sample apps", func() {
+			Expect(execute("version")).To(Succeed())
+		})
+
+		It("should fail commands that use sample apps", func() {
+			err := execute("extract", "golang", "--target", home)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("failed to load user-defined sample apps"))
+		})
+	})
+})
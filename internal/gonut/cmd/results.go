@@ -0,0 +1,344 @@
+// Copyright © 2019 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gonvenience/bunt"
+	"github.com/homeport/gonut/internal/gonut/cf"
+)
+
+// pushResult is the outcome of a single sample app push attempt
+type pushResult struct {
+	caption     string
+	stack       string
+	buildpack   string
+	lifecycle   string
+	healthCheck string
+	report      *cf.PushReport
+	skipReason  string
+	err         error
+}
+
+// name returns a descriptive name of the push, including the settings that
+// differentiate it from other pushes of the same sample app
+func (result pushResult) name() string {
+	parts := []string{result.caption, "on", stackCaption(result.stack)}
+
+	if result.lifecycle != "" && result.lifecycle != cf.BuildpackLifecycle {
+		parts = append(parts, "with", result.lifecycle, "lifecycle")
+	}
+
+	if result.healthCheck != "" {
+		parts = append(parts, "with", result.healthCheck, "health check")
+	}
+
+	return strings.Join(parts, " ")
+}
+
+var (
+	runName     = "gonut push"
+	runStart    = time.Now()
+	pushResults []pushResult
+)
+
+// timestamp returns the start of the push, or the start of the run for
+// results without a report, for example pushes that failed early
+func (result pushResult) timestamp() time.Time {
+	if result.report != nil && !result.report.InitStart.IsZero() {
+		return result.report.InitStart
+	}
+
+	return runStart
+}
+
+func recordPushResult(app *sampleApp, lifecycle string, healthCheck string, report *cf.PushReport, skipReason string, err error) {
+	pushResults = append(pushResults, pushResult{
+		caption:     app.caption,
+		stack:       app.stack,
+		buildpack:   app.buildpack,
+		lifecycle:   lifecycle,
+		healthCheck: healthCheck,
+		report:      report,
+		skipReason:  skipReason,
+		err:         err,
+	})
+}
+
+// skipSampleAppPush lets the user know that the push of the sample app is
+// skipped and returns the reason as plain text
+func skipSampleAppPush(app *sampleApp, format string, args ...interface{}) string {
+	reason := bunt.Sprintf(format, args...)
+
+	if strings.ToLower(outputSetting) != "junit" {
+		bunt.Printf("Skipping push of *%s* sample app, because %s.\n",
+			app.caption,
+			reason,
+		)
+	}
+
+	return bunt.RemoveAllEscapeSequences(reason)
+}
+
+// isHumanReadableOutput returns true if the output setting is one of the
+// settings that are meant to be read by humans rather than tools
+func isHumanReadableOutput() bool {
+	switch strings.ToLower(outputSetting) {
+	case "short", "oneline", "full":
+		return true
+
+	default:
+		return false
+	}
+}
+
+// writePushResults writes the collected push results to the configured
+// report destinations
+func writePushResults() error {
+	// Notifications need to be created before the history is updated, since
+	// a recovery is detected using the previous result in the history. A
+	// failing webhook must not prevent the other results from being written.
+	var notifyErr error
+	if len(webhooks) > 0 {
+		notifications, err := pushNotifications(pushResults)
+		if err != nil {
+			return err
+		}
+
+		notifyErr = cf.SendNotifications(webhooks, notifications)
+	}
+
+	if !noHistorySetting {
+		if err := cf.AppendHistory(cf.HistoryPath(), historyEntries(pushResults)...); err != nil {
+			return fmt.Errorf("failed to add push results to history: %w", err)
+		}
+	}
+
+	if strings.ToLower(outputSetting) == "junit" || junitFileSetting != "" {
+		data, err := junitReport().ToXML()
+		if err != nil {
+			return err
+		}
+
+		if strings.ToLower(outputSetting) == "junit" {
+			fmt.Println(string(data))
+		}
+
+		if junitFileSetting != "" {
+			if err := os.WriteFile(junitFileSetting, data, 0644); err != nil {
+				return fmt.Errorf("failed to write JUnit report to %s: %w", junitFileSetting, err)
+			}
+		}
+	}
+
+	if prometheusFileSetting != "" {
+		if err := cf.WritePrometheusFile(prometheusFileSetting, cf.LatestPushMetrics(pushMetrics(pushResults))); err != nil {
+			return fmt.Errorf("failed to write Prometheus metrics to %s: %w", prometheusFileSetting, err)
+		}
+	}
+
+	if pushgatewaySetting != "" {
+		if err := cf.PushToGateway(pushgatewaySetting, "gonut", cf.LatestPushMetrics(pushMetrics(pushResults))); err != nil {
+			return fmt.Errorf("failed to send Prometheus metrics to %s: %w", pushgatewaySetting, err)
+		}
+	}
+
+	if otlpEndpointSetting != "" || traceFileSetting != "" {
+		traces := pushTrace()
+
+		if traceFileSetting != "" {
+			data, err := traces.ToJSON()
+			if err != nil {
+				return err
+			}
+
+			if err := os.WriteFile(traceFileSetting, data, 0644); err != nil {
+				return fmt.Errorf("failed to write trace to %s: %w", traceFileSetting, err)
+			}
+		}
+
+		if otlpEndpointSetting != "" {
+			if err := cf.ExportTraces(otlpEndpointSetting, traces); err != nil {
+				return fmt.Errorf("failed to send trace to %s: %w", otlpEndpointSetting, err)
+			}
+		}
+	}
+
+	return notifyErr
+}
+
+func historyEntries(results []pushResult) []cf.HistoryEntry {
+	var result []cf.HistoryEntry
+	for _, pushResult := range results {
+		if pushResult.skipReason != "" {
+			continue
+		}
+
+		result = append(result, pushResult.historyEntry())
+	}
+
+	return result
+}
+
+func (result pushResult) historyEntry() cf.HistoryEntry {
+	entry := cf.NewHistoryEntry(result.caption, result.report, result.err)
+	entry.GonutVersion = gonutVersion()
+	entry.Lifecycle = result.lifecycle
+	entry.HealthCheck = result.healthCheck
+
+	if entry.Stack == "" {
+		entry.Stack = result.stack
+	}
+
+	if entry.Buildpack == "" {
+		entry.Buildpack = result.buildpack
+	}
+
+	return entry
+}
+
+// sessionEntries contains the most recent history entry of each push setup
+// of this gonut process, so that recoveries are noticed even without history
+var sessionEntries []cf.HistoryEntry
+
+// pushNotifications creates a notification for each failed push, and for
+// each successful push for which the previous push with the same setup failed
+func pushNotifications(results []pushResult) ([]cf.Notification, error) {
+	previous, err := cf.LoadHistory(cf.HistoryPath())
+	if err != nil {
+		return nil, fmt.Errorf("failed to load history from %s: %w", cf.HistoryPath(), err)
+	}
+
+	previous = append(previous, sessionEntries...)
+
+	var notifications []cf.Notification
+	for _, result := range results {
+		if result.skipReason != "" {
+			continue
+		}
+
+		entry := result.historyEntry()
+		switch {
+		case result.err != nil:
+			notifications = append(notifications, cf.NewNotification(cf.FailureEvent, entry, result.report, result.err))
+
+		case previousPushFailed(previous, entry):
+			notifications = append(notifications, cf.NewNotification(cf.RecoveryEvent, entry, result.report, nil))
+		}
+
+		sessionEntries = append(removeSameSetup(sessionEntries, entry), entry)
+	}
+
+	return notifications, nil
+}
+
+func previousPushFailed(entries []cf.HistoryEntry, entry cf.HistoryEntry) bool {
+	for i := len(entries) - 1; i >= 0; i-- {
+		if entries[i].SameSetup(entry) {
+			return !entries[i].Success
+		}
+	}
+
+	return false
+}
+
+func removeSameSetup(entries []cf.HistoryEntry, entry cf.HistoryEntry) []cf.HistoryEntry {
+	var result []cf.HistoryEntry
+	for _, candidate := range entries {
+		if !candidate.SameSetup(entry) {
+			result = append(result, candidate)
+		}
+	}
+
+	return result
+}
+
+func pushTrace() cf.OTLPTraces {
+	var pushes []cf.TracedPush
+	for _, result := range pushResults {
+		attributes := map[string]string{
+			"gonut.app":       result.caption,
+			"gonut.stack":     stackCaption(result.stack),
+			"gonut.buildpack": result.buildpack,
+			"gonut.lifecycle": result.lifecycle,
+		}
+
+		if result.healthCheck != "" {
+			attributes["gonut.health_check"] = result.healthCheck
+		}
+
+		if result.report != nil {
+			attributes["cf.app_name"] = result.report.AppName
+		}
+
+		pushes = append(pushes, cf.TracedPush{
+			Name:       result.name(),
+			Report:     result.report,
+			Err:        result.err,
+			Attributes: attributes,
+		})
+	}
+
+	return cf.NewPushTrace(runName, gonutVersion(), runStart, time.Now(), pushes)
+}
+
+func pushMetrics(results []pushResult) []cf.PushMetrics {
+	var result []cf.PushMetrics
+	for _, pushResult := range results {
+		// Skipped pushes are not part of the metrics, they did not happen
+		if pushResult.skipReason != "" {
+			continue
+		}
+
+		result = append(result, cf.PushMetrics{
+			App:         pushResult.caption,
+			Stack:       pushResult.stack,
+			Buildpack:   pushResult.buildpack,
+			Lifecycle:   pushResult.lifecycle,
+			HealthCheck: pushResult.healthCheck,
+			Report:      pushResult.report,
+			Success:     pushResult.err == nil,
+			Timestamp:   pushResult.timestamp(),
+		})
+	}
+
+	return result
+}
+
+func junitReport() cf.JUnitTestSuites {
+	testCases := make([]cf.JUnitTestCase, len(pushResults))
+	for i, result := range pushResults {
+		// Only use the report of successful pushes, since the timings of a
+		// failed push are incomplete
+		report := result.report
+		if result.err != nil {
+			report = nil
+		}
+
+		testCases[i] = cf.NewJUnitTestCase(result.name(), report, result.skipReason, result.err)
+	}
+
+	return cf.NewJUnitTestSuites(runName, runStart, testCases)
+}
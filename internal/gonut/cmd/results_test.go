@@ -0,0 +1,112 @@
+// Copyright © 2019 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"errors"
+	"os"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/homeport/gonut/internal/gonut/cf"
+)
+
+var _ = Describe("Push notifications", func() {
+	var (
+		home    string
+		restore []func()
+	)
+
+	setEnv := func(key string, value string) {
+		if previous, ok := os.LookupEnv(key); ok {
+			restore = append(restore, func() { os.Setenv(key, previous) })
+		} else {
+			restore = append(restore, func() { os.Unsetenv(key) })
+		}
+
+		os.Setenv(key, value)
+	}
+
+	events := func(notifications []cf.Notification) []string {
+		var result []string
+		for _, notification := range notifications {
+			result = append(result, notification.Event)
+		}
+
+		return result
+	}
+
+	// A failed push does not know the stack unless it was requested, while
+	// a successful push reports the stack that was actually used
+	failed := pushResult{caption: "Golang", err: errors.New("staging failed")}
+	succeeded := pushResult{caption: "Golang", stack: "cflinuxfs4", buildpack: "go_buildpack"}
+
+	BeforeEach(func() {
+		var err error
+		home, err = os.MkdirTemp("", "gonut-home")
+		Expect(err).ToNot(HaveOccurred())
+
+		setEnv("HOME", home)
+		setEnv("CF_HOME", home)
+		sessionEntries = nil
+	})
+
+	AfterEach(func() {
+		for i := len(restore) - 1; i >= 0; i-- {
+			restore[i]()
+		}
+
+		restore = nil
+		sessionEntries = nil
+		Expect(os.RemoveAll(home)).To(Succeed())
+	})
+
+	It("should notify about a recovery if the previous push in the history failed", func() {
+		Expect(cf.AppendHistory(cf.HistoryPath(), failed.historyEntry())).To(Succeed())
+
+		notifications, err := pushNotifications([]pushResult{succeeded})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(events(notifications)).To(Equal([]string{cf.RecoveryEvent}))
+	})
+
+	It("should notify about a recovery if the previous push of this session failed", func() {
+		notifications, err := pushNotifications([]pushResult{failed})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(events(notifications)).To(Equal([]string{cf.FailureEvent}))
+
+		notifications, err = pushNotifications([]pushResult{succeeded})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(events(notifications)).To(Equal([]string{cf.RecoveryEvent}))
+
+		notifications, err = pushNotifications([]pushResult{succeeded})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(notifications).To(BeEmpty())
+	})
+
+	It("should not notify about a recovery if the previous push succeeded", func() {
+		Expect(cf.AppendHistory(cf.HistoryPath(), succeeded.historyEntry())).To(Succeed())
+
+		notifications, err := pushNotifications([]pushResult{succeeded})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(notifications).To(BeEmpty())
+	})
+})
@@ -52,10 +52,19 @@ func Execute() {
 	if err := rootCmd.Execute(); err != nil {
 		ExitGonut(err)
 	}
+
+	cleanUpTarget()
 }
 
 // ExitGonut leaves gonut in case of an unresolvable error situation
 func ExitGonut(reason interface{}) {
+	cleanUpTarget()
+	printError(reason)
+	os.Exit(1)
+}
+
+// printError shows the error in a box on standard error
+func printError(reason interface{}) {
 	var (
 		headline string
 		content  string
@@ -80,6 +89,4 @@ func ExitGonut(reason interface{}) {
 		neat.HeadlineColor(bunt.Coral),
 		neat.ContentColor(bunt.DimGray),
 	)
-
-	os.Exit(1)
 }
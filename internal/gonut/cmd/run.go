@@ -0,0 +1,257 @@
+// Copyright © 2019 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/gonvenience/bunt"
+	"github.com/gonvenience/neat"
+	"github.com/homeport/gonut/internal/gonut/cf"
+	"github.com/homeport/gonut/internal/gonut/nok"
+	"github.com/spf13/cobra"
+)
+
+// runCmd represents the run command
+var runCmd = &cobra.Command{
+	Use:   "run <suite.yml>",
+	Short: "Run a suite of sample app pushes",
+	Long: `Runs all sample app pushes described in the given suite file and reports
+the combined result. Each test of the suite defines the sample app or git URL
+to push, and optionally the stacks, buildpacks, lifecycle, health check type,
+manifest overrides, probes, and push duration thresholds to use. Settings that
+are not defined in a test are taken from the command line flags.
+
+A failed push does not stop the suite.`,
+	Args:          cobra.ExactArgs(1),
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE:          runCommandFunc,
+}
+
+func init() {
+	rootCmd.AddCommand(runCmd)
+	addPushFlags(runCmd.Flags())
+
+	// A suite always continues after failed pushes
+	_ = runCmd.Flags().MarkHidden("matrix")
+}
+
+// suiteSettings contains the push settings that a suite test can override
+type suiteSettings struct {
+	buildpack   string
+	lifecycle   string
+	healthCheck string
+	noPing      bool
+	taskCheck   bool
+	sshCheck    bool
+	thresholds  cf.Thresholds
+}
+
+func runCommandFunc(cmd *cobra.Command, args []string) error {
+	if err := registerUserApps(); err != nil {
+		return err
+	}
+
+	suite, err := cf.LoadSuite(args[0])
+	if err != nil {
+		return err
+	}
+
+	// Make sure all sample apps exist before the first push starts
+	for _, test := range suite.Tests {
+		if lookUpSampleApp(test.App) == nil {
+			return fmt.Errorf("could not find %s sample app of suite %s. Please use a git URL or an app from the following list:\n\n%s", test.App, suite.Name, getOptions())
+		}
+	}
+
+	if err := preparePushSettings(); err != nil {
+		return err
+	}
+
+	runName = suite.Name
+
+	suiteErr := runSuite(suite)
+	if err := writePushResults(); err != nil {
+		return err
+	}
+
+	return suiteErr
+}
+
+// runSuite pushes the sample apps of all tests of the suite, and does not
+// stop in case a push fails
+func runSuite(suite *cf.Suite) error {
+	defaults := currentSuiteSettings()
+	defer defaults.apply()
+
+	var failed []string
+	for _, test := range suite.Tests {
+		settings, err := defaults.with(test)
+		if err != nil {
+			return err
+		}
+
+		settings.apply()
+
+		lifecycles, err := getLifecycles()
+		if err != nil {
+			return err
+		}
+
+		healthChecks, err := getHealthChecks()
+		if err != nil {
+			return err
+		}
+
+		stacks, err := suiteStacks(test)
+		if err != nil {
+			return err
+		}
+
+		buildpacks := test.Buildpacks
+		if len(buildpacks) == 0 {
+			buildpacks = []string{defaults.buildpack}
+		}
+
+		for _, buildpack := range buildpacks {
+			buildpackSetting = buildpack
+
+			for _, stack := range stacks {
+				app := *lookUpSampleApp(test.App)
+				app.stack = stack // Empty if not set
+				app.manifest = test.Manifest
+
+				if _, err := runSampleAppPushes(&app, lifecycles, healthChecks); err != nil {
+					failed = append(failed, fmt.Sprintf("%s sample app on %s: %v", app.caption, stackCaption(stack), err))
+				}
+			}
+		}
+	}
+
+	if isHumanReadableOutput() {
+		content, err := neat.Table(suiteTable(pushResults), neat.AlignRight(0))
+		if err != nil {
+			return err
+		}
+
+		neat.Box(os.Stdout, bunt.Sprintf("Result of suite *%s*", suite.Name), strings.NewReader(content))
+	}
+
+	if len(failed) > 0 {
+		return nok.Errorf(
+			fmt.Sprintf("%d sample app pushes of suite %s failed", len(failed), suite.Name),
+			strings.Join(failed, "\n\n"),
+		)
+	}
+
+	return nil
+}
+
+// suiteStacks returns the stacks of the test, or the stacks based on the
+// stack setting if the test does not define any
+func suiteStacks(test cf.SuiteTest) ([]string, error) {
+	if len(test.Stacks) == 0 {
+		return getStacks()
+	}
+
+	for _, stack := range test.Stacks {
+		if stack == "all" {
+			return cf.GetStackNames()
+		}
+	}
+
+	return test.Stacks, nil
+}
+
+func suiteTable(results []pushResult) [][]string {
+	rows := [][]string{{"", bunt.Sprint("*result*"), bunt.Sprint("*total*")}}
+	for _, result := range results {
+		row := []string{bunt.Sprintf("DimGray{_%s_}", result.name())}
+		switch {
+		case result.err != nil:
+			row = append(row, bunt.Sprint("Crimson{fail}"), "")
+
+		case result.skipReason != "":
+			row = append(row, bunt.Sprint("DimGray{skipped}"), "")
+
+		default:
+			row = append(row,
+				bunt.Sprint("DarkSeaGreen{pass}"),
+				bunt.Sprintf("SteelBlue{%s}", cf.HumanReadableDuration(result.report.ElapsedTime())),
+			)
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows
+}
+
+func currentSuiteSettings() suiteSettings {
+	return suiteSettings{
+		buildpack:   buildpackSetting,
+		lifecycle:   lifecycleSetting,
+		healthCheck: healthCheckSetting,
+		noPing:      noPingSetting,
+		taskCheck:   taskCheckSetting,
+		sshCheck:    sshCheckSetting,
+		thresholds:  thresholds,
+	}
+}
+
+// with returns the settings overridden by the settings of the suite test
+func (settings suiteSettings) with(test cf.SuiteTest) (suiteSettings, error) {
+	if test.Lifecycle != "" {
+		settings.lifecycle = test.Lifecycle
+	}
+
+	if test.HealthCheck != "" {
+		settings.healthCheck = test.HealthCheck
+	}
+
+	if test.Probes != nil {
+		settings.noPing = !test.HasProbe(cf.PingProbe)
+		settings.taskCheck = test.HasProbe(cf.TaskProbe)
+		settings.sshCheck = test.HasProbe(cf.SSHProbe)
+	}
+
+	limits, err := test.ParsedThresholds()
+	if err != nil {
+		return settings, err
+	}
+
+	settings.thresholds = settings.thresholds.Merge(limits)
+
+	return settings, nil
+}
+
+func (settings suiteSettings) apply() {
+	buildpackSetting = settings.buildpack
+	lifecycleSetting = settings.lifecycle
+	healthCheckSetting = settings.healthCheck
+	noPingSetting = settings.noPing
+	taskCheckSetting = settings.taskCheck
+	sshCheckSetting = settings.sshCheck
+	thresholds = settings.thresholds
+}
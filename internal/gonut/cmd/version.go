@@ -50,16 +50,21 @@ func init() {
 
 // GetVersion returns the version of the tool
 func GetVersion() string {
-	if len(version) == 0 {
-		version = "development"
-	}
-
 	lightblue, _ := colorful.MakeColor(color.RGBA{77, 173, 233, 255})
 	otherblue, _ := colorful.MakeColor(color.RGBA{63, 143, 231, 255})
 
 	return fmt.Sprintf("%s%s version %s\n",
 		bunt.Style("go", bunt.Foreground(lightblue)),
 		bunt.Style("nut", bunt.Foreground(otherblue), bunt.Bold()),
-		bunt.Style(version, bunt.Foreground(bunt.DimGray)),
+		bunt.Style(gonutVersion(), bunt.Foreground(bunt.DimGray)),
 	)
 }
+
+// gonutVersion returns the plain version string of the tool
+func gonutVersion() string {
+	if len(version) == 0 {
+		version = "development"
+	}
+
+	return version
+}